@@ -0,0 +1,221 @@
+// Package aiff reads and writes AIFF and AIFF-C files.
+//
+// Supported AIFF-C compression types are big-endian (NONE, twos) and little-endian (sowt) PCM,
+// 32 and 64-bit floats (fl32, fl64), A-law and µ-law.
+package aiff
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/BeatGlow/audio/internal/chunk"
+)
+
+var (
+	ErrNotAIFF  = errors.New("aiff: not an AIFF or AIFF-C file")
+	ErrFormat   = errors.New("aiff: missing or invalid COMM chunk")
+	ErrData     = errors.New("aiff: missing SSND chunk")
+	ErrClosed   = errors.New("aiff: writer is closed")
+	ErrTooLarge = errors.New("aiff: data exceeds the size of a FORM chunk")
+)
+
+// Compression types of AIFF-C files.
+const (
+	CompressionNone    = "NONE" // big-endian PCM, the format of AIFF files
+	CompressionTwos    = "twos" // big-endian PCM
+	CompressionSowt    = "sowt" // little-endian PCM
+	CompressionRaw     = "raw " // unsigned 8-bit PCM
+	CompressionFloat32 = "fl32"
+	CompressionFloat64 = "fl64"
+	CompressionALaw    = "alaw"
+	CompressionMuLaw   = "ulaw"
+)
+
+// compressionNames are the names written after the compression types.
+var compressionNames = map[string]string{
+	CompressionNone:    "not compressed",
+	CompressionSowt:    "",
+	CompressionFloat32: "32-bit floating point",
+	CompressionFloat64: "64-bit floating point",
+	CompressionALaw:    "ALaw 2:1",
+	CompressionMuLaw:   "µLaw 2:1",
+}
+
+// aifcVersion is the timestamp of the AIFF-C version in the FVER chunk.
+const aifcVersion = 0xa2805140
+
+// Metadata are the optional chunks of an AIFF file.
+type Metadata struct {
+	// Markers are the positions of the MARK chunk.
+	Markers []Marker
+
+	// Instrument is the INST chunk, or nil.
+	Instrument *Instrument
+}
+
+// Marker is a named position in the samples.
+type Marker struct {
+	// ID identifies the marker, for example in a Loop. It must be positive.
+	ID int16
+
+	// Position is the frame before which the marker is placed.
+	Position uint32
+
+	Name string
+}
+
+// Instrument contains the information for samplers in the INST chunk.
+type Instrument struct {
+	BaseNote     uint8 // MIDI note played at the sample rate
+	Detune       int8  // in cents, from -50 to 50
+	LowNote      uint8
+	HighNote     uint8
+	LowVelocity  uint8
+	HighVelocity uint8
+	Gain         int16 // in dB
+	SustainLoop  Loop
+	ReleaseLoop  Loop
+}
+
+// PlayMode is how a loop is played.
+type PlayMode int16
+
+const (
+	NoLooping PlayMode = iota
+	ForwardLooping
+	ForwardBackwardLooping
+)
+
+// Loop is a section of the samples between two markers.
+type Loop struct {
+	PlayMode PlayMode
+	Begin    int16 // marker ID of the start of the loop
+	End      int16 // marker ID of the end of the loop
+}
+
+// extended converts an 80-bit IEEE 754 extended precision number.
+func extended(b []byte) float64 {
+	var (
+		exponent = int(binary.BigEndian.Uint16(b) & 0x7fff)
+		mantissa = binary.BigEndian.Uint64(b[2:])
+		v        = math.Ldexp(float64(mantissa), exponent-16383-63)
+	)
+	if b[0]&0x80 != 0 {
+		return -v
+	}
+	return v
+}
+
+// appendExtended appends v as an 80-bit IEEE 754 extended precision number.
+func appendExtended(b []byte, v float64) []byte {
+	if v == 0 {
+		return append(b, make([]byte, 10)...)
+	}
+	var sign uint16
+	if v < 0 {
+		sign, v = 0x8000, -v
+	}
+	fraction, exponent := math.Frexp(v)
+	b = binary.BigEndian.AppendUint16(b, sign|uint16(exponent-1+16383))
+	return binary.BigEndian.AppendUint64(b, uint64(math.Ldexp(fraction, 64)))
+}
+
+// pstring parses a Pascal string padded to an even size, and returns the remaining bytes.
+func pstring(b []byte) (string, []byte) {
+	if len(b) == 0 {
+		return "", nil
+	}
+	n := min(int(b[0]), len(b)-1)
+	s := string(b[1 : 1+n])
+	return s, b[min(len(b), int(chunk.Padded(int64(n+1)))):]
+}
+
+// appendPstring appends a Pascal string padded to an even size.
+func appendPstring(b []byte, s string) []byte {
+	if len(s) > 255 {
+		s = s[:255]
+	}
+	b = append(append(b, byte(len(s))), s...)
+	if len(s)&1 == 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// parseMarkers parses a MARK chunk.
+func parseMarkers(b []byte) []Marker {
+	if len(b) < 2 {
+		return nil
+	}
+	count := int(binary.BigEndian.Uint16(b))
+	b = b[2:]
+	markers := make([]Marker, 0, min(count, len(b)/8))
+	for i := 0; i < count && len(b) >= 7; i++ {
+		m := Marker{
+			ID:       int16(binary.BigEndian.Uint16(b)),
+			Position: binary.BigEndian.Uint32(b[2:]),
+		}
+		m.Name, b = pstring(b[6:])
+		markers = append(markers, m)
+	}
+	return markers
+}
+
+// parseInstrument parses an INST chunk.
+func parseInstrument(b []byte) *Instrument {
+	if len(b) < 20 {
+		return nil
+	}
+	loop := func(b []byte) Loop {
+		return Loop{
+			PlayMode: PlayMode(binary.BigEndian.Uint16(b)),
+			Begin:    int16(binary.BigEndian.Uint16(b[2:])),
+			End:      int16(binary.BigEndian.Uint16(b[4:])),
+		}
+	}
+	return &Instrument{
+		BaseNote:     b[0],
+		Detune:       int8(b[1]),
+		LowNote:      b[2],
+		HighNote:     b[3],
+		LowVelocity:  b[4],
+		HighVelocity: b[5],
+		Gain:         int16(binary.BigEndian.Uint16(b[6:])),
+		SustainLoop:  loop(b[8:]),
+		ReleaseLoop:  loop(b[14:]),
+	}
+}
+
+// encode the metadata as chunks.
+func (m Metadata) encode(w io.Writer) error {
+	if len(m.Markers) > 0 {
+		body := binary.BigEndian.AppendUint16(nil, uint16(len(m.Markers)))
+		for _, marker := range m.Markers {
+			body = binary.BigEndian.AppendUint16(body, uint16(marker.ID))
+			body = binary.BigEndian.AppendUint32(body, marker.Position)
+			body = appendPstring(body, marker.Name)
+		}
+		if err := chunk.Write(w, binary.BigEndian, "MARK", body); err != nil {
+			return err
+		}
+	}
+
+	if inst := m.Instrument; inst != nil {
+		body := []byte{
+			inst.BaseNote, byte(inst.Detune), inst.LowNote, inst.HighNote,
+			inst.LowVelocity, inst.HighVelocity,
+		}
+		body = binary.BigEndian.AppendUint16(body, uint16(inst.Gain))
+		for _, l := range []Loop{inst.SustainLoop, inst.ReleaseLoop} {
+			body = binary.BigEndian.AppendUint16(body, uint16(l.PlayMode))
+			body = binary.BigEndian.AppendUint16(body, uint16(l.Begin))
+			body = binary.BigEndian.AppendUint16(body, uint16(l.End))
+		}
+		if err := chunk.Write(w, binary.BigEndian, "INST", body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
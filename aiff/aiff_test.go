@@ -0,0 +1,256 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/audiotest"
+)
+
+// write writes samples to a file in the format, with the metadata.
+func write[T audio.Sample](t *testing.T, format audio.Format, metadata Metadata, samples audio.Samples[T]) []byte {
+	t.Helper()
+	return audiotest.Write(t, func(f *audiotest.File) (*Writer[T], error) {
+		w, err := NewWriter[T](f, format)
+		if err == nil {
+			w.Metadata = metadata
+		}
+		return w, err
+	}, samples, ErrClosed)
+}
+
+func TestExtended(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Value float64
+		Bytes []byte
+	}{
+		{"zero", 0, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{"8000", 8000, []byte{0x40, 0x0b, 0xfa, 0, 0, 0, 0, 0, 0, 0}},
+		{"44100", 44100, []byte{0x40, 0x0e, 0xac, 0x44, 0, 0, 0, 0, 0, 0}},
+		{"negative", -0.5, []byte{0xbf, 0xfe, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			if b := appendExtended(nil, test.Value); !bytes.Equal(b, test.Bytes) {
+				it.Errorf("expected % x, got % x", test.Bytes, b)
+			}
+			if v := extended(test.Bytes); v != test.Value {
+				it.Errorf("expected %g, got %g", test.Value, v)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Format      audio.Format
+		Form        string
+		Compression string
+	}{
+		{"s8", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.Unsigned, Bits: 8}, "AIFF", CompressionNone},
+		{"s16be", audio.Format{SampleRate: 44100, Channels: 2, Encoding: audio.Signed, Bits: 16, ByteOrder: binary.BigEndian}, "AIFF", CompressionNone},
+		{"s16le", audio.Format{SampleRate: 44100, Channels: 2, Encoding: audio.Signed, Bits: 16, ByteOrder: binary.LittleEndian}, "AIFC", CompressionSowt},
+		{"s24", audio.Format{SampleRate: 48000, Channels: 2, Encoding: audio.Signed, Bits: 24, ByteOrder: binary.BigEndian}, "AIFF", CompressionNone},
+		{"f32", audio.Format{SampleRate: 96000, Channels: 1, Encoding: audio.Float, Bits: 32, ByteOrder: binary.LittleEndian}, "AIFC", CompressionFloat32},
+		{"f64", audio.Format{SampleRate: 96000, Channels: 1, Encoding: audio.Float, Bits: 64, ByteOrder: binary.BigEndian}, "AIFC", CompressionFloat64},
+		{"ulaw", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.MuLaw, Bits: 8}, "AIFC", CompressionMuLaw},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			samples := audiotest.Ramp(test.Format.Channels, 7)
+			data := write(it, test.Format, Metadata{}, samples)
+			if form := string(data[8:12]); form != test.Form {
+				it.Errorf("expected %s, got %s", test.Form, form)
+			}
+			if size := int(binary.BigEndian.Uint32(data[4:])); size != len(data)-8 {
+				it.Errorf("expected FORM size %d, got %d", len(data)-8, size)
+			}
+
+			r, err := NewReader[int16](bytes.NewReader(data))
+			if err != nil {
+				it.Fatal(err)
+			}
+			if r.Compression() != test.Compression {
+				it.Errorf("expected %q, got %q", test.Compression, r.Compression())
+			}
+			format := r.Format()
+			if format.SampleRate != test.Format.SampleRate || format.Channels != test.Format.Channels {
+				it.Errorf("expected format %s, got %s", test.Format, format)
+			}
+			if test.Format.Bits > 8 && test.Format.Encoding != audio.Float && format.ByteOrder != test.Format.ByteOrder {
+				it.Errorf("expected %s, got %s", test.Format.ByteOrder, format.ByteOrder)
+			}
+			if r.Frames() != 7 {
+				it.Errorf("expected 7 frames, got %d", r.Frames())
+			}
+
+			audiotest.Near(it, audiotest.ReadAll(it, r), samples)
+		})
+	}
+}
+
+func TestSampleSize(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Format audio.Format
+		Size   int
+		Bytes  int
+	}{
+		{"s12", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.Signed, Bits: 12, ByteOrder: binary.BigEndian}, 12, 2},
+		{"s24in32", audio.Format{SampleRate: 48000, Channels: 2, Encoding: audio.Signed, Bits: 24, Container: 32, ByteOrder: binary.BigEndian}, 24, 3},
+		{"s32", audio.Format{SampleRate: 48000, Channels: 2, Encoding: audio.Signed, Bits: 32, ByteOrder: binary.BigEndian}, 32, 4},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			samples := audio.Samples[int32]{0x40000000, -0x40000000, 0x12345678, -1 << 31}[:2*test.Format.Channels]
+			data := audiotest.Write(it, func(f *audiotest.File) (*Writer[int32], error) {
+				w, err := NewWriter[int32](f, test.Format)
+				if err == nil && w.SampleSize() != test.Size {
+					it.Errorf("expected a sample size of %d, got %d", test.Size, w.SampleSize())
+				}
+				return w, err
+			}, samples, ErrClosed)
+
+			r, err := NewReader[int32](bytes.NewReader(data))
+			if err != nil {
+				it.Fatal(err)
+			}
+			if r.SampleSize() != test.Size {
+				it.Errorf("expected a sample size of %d, got %d", test.Size, r.SampleSize())
+			}
+			if n := r.Format().BytesPerSample(); n != test.Bytes {
+				it.Errorf("expected %d bytes per sample, got %d", test.Bytes, n)
+			}
+			// The samples are stored in the most significant bits.
+			got := audiotest.ReadAll(it, r)
+			if len(got) != len(samples) {
+				it.Fatalf("expected %d samples, got %d", len(samples), len(got))
+			}
+			for i, v := range got {
+				if diff := int64(v) - int64(samples[i]); diff < -1<<(32-test.Size) || diff > 1<<(32-test.Size) {
+					it.Errorf("sample %d: expected %#x, got %#x", i, samples[i], v)
+				}
+			}
+		})
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	format := audio.Format{SampleRate: 22050, Channels: 1, Encoding: audio.Signed, Bits: 8}
+	metadata := Metadata{
+		Markers: []Marker{{ID: 1, Position: 1, Name: "start"}, {ID: 2, Position: 4, Name: "end!"}},
+		Instrument: &Instrument{
+			BaseNote:     60,
+			Detune:       -10,
+			HighNote:     127,
+			HighVelocity: 127,
+			Gain:         -3,
+			SustainLoop:  Loop{PlayMode: ForwardBackwardLooping, Begin: 1, End: 2},
+		},
+	}
+	// An odd number of samples needs a pad byte before the metadata.
+	data := write(t, format, metadata, make(audio.Samples[int8], 5))
+
+	r, err := NewReader[int8](bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Metadata, metadata) {
+		t.Errorf("expected %+v, got %+v", metadata, r.Metadata)
+	}
+
+	// Without seeking, the metadata after the samples isn't read.
+	r, err = NewReader[int8](io.MultiReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Markers != nil || r.Instrument != nil {
+		t.Errorf("expected no metadata, got %+v", r.Metadata)
+	}
+	if n := len(audiotest.ReadAll(t, r)); n != 5 {
+		t.Errorf("expected 5 samples, got %d", n)
+	}
+}
+
+func TestReader(t *testing.T) {
+	// A hand-made AIFF file with 12-bit samples, an SSND offset and an unknown chunk.
+	var b []byte
+	chunk := func(id string, body []byte) {
+		b = append(b, id...)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(body)))
+		b = append(b, body...)
+		if len(body)&1 == 1 {
+			b = append(b, 0)
+		}
+	}
+	comm := []byte{0, 1, 0, 0, 0, 2, 0, 12}
+	comm = appendExtended(comm, 8000)
+	chunk("COMM", comm)
+	chunk("ANNO", []byte("odd"))
+	chunk("SSND", []byte{0, 0, 0, 2, 0, 0, 0, 0, 0xff, 0xff, 0x40, 0x00, 0xc0, 0x00, 0x12, 0x34})
+
+	data := append([]byte("FORM\x00\x00\x00\x00AIFF"), b...)
+	binary.BigEndian.PutUint32(data[4:], uint32(len(data)-8))
+
+	r, err := NewReader[int16](bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.SampleSize() != 12 {
+		t.Errorf("expected a sample size of 12, got %d", r.SampleSize())
+	}
+	if got, expected := r.String(), `aiff "NONE", `+r.Format().String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	// The data after the frames in the COMM chunk is ignored.
+	if got := audiotest.ReadAll(t, r); !reflect.DeepEqual(got, audio.Samples[int16]{0x4000, -0x4000}) {
+		t.Errorf("unexpected samples %v", got)
+	}
+
+	position, err := audio.NewPosition[int16](r, r.Format())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = position.SeekFrame(1, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if got := audiotest.ReadAll(t, r); !reflect.DeepEqual(got, audio.Samples[int16]{-0x4000}) {
+		t.Errorf("unexpected samples after seeking %v", got)
+	}
+
+	// The file can start after the beginning of the io.Reader.
+	embedded := bytes.NewReader(append([]byte("junk"), data...))
+	if _, err = embedded.Seek(4, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if r, err = NewReader[int16](embedded); err != nil {
+		t.Fatal(err)
+	}
+	if got := audiotest.ReadAll(t, r); !reflect.DeepEqual(got, audio.Samples[int16]{0x4000, -0x4000}) {
+		t.Errorf("unexpected samples in an embedded file %v", got)
+	}
+
+	testCases := []struct {
+		Name string
+		Data []byte
+		Err  error
+	}{
+		{"empty", nil, ErrNotAIFF},
+		{"not aiff", []byte("FORM\x04\x00\x00\x008SVX"), ErrNotAIFF},
+		{"no data", data[:12+8+len(comm)], ErrData},
+		{"no comm", append([]byte("FORM\x00\x00\x00\x10AIFF"), "SSND\x00\x00\x00\x08\x00\x00\x00\x00\x00\x00\x00\x00"...), ErrFormat},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			if _, err := NewReader[int16](bytes.NewReader(test.Data)); err != test.Err {
+				it.Errorf("expected %v, got %v", test.Err, err)
+			}
+		})
+	}
+}
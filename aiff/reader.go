@@ -0,0 +1,266 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/chunk"
+	"github.com/BeatGlow/audio/internal/pcm"
+)
+
+// Reader reads samples from an AIFF or AIFF-C file.
+type Reader[T audio.Sample] struct {
+	// Metadata of the file. Chunks after the samples are only parsed if the io.Reader can seek.
+	Metadata
+
+	format      audio.Format
+	compression string
+	sampleSize  int
+	frames      int64
+	reader      audio.FormatReader[T]
+}
+
+// header is the parsed COMM chunk.
+type header struct {
+	channels    int
+	frames      int64
+	sampleSize  int
+	sampleRate  float64
+	compression string
+}
+
+// NewReader parses the header of an AIFF or AIFF-C file read from r, and returns a Reader for its
+// samples converted to T.
+//
+// If r is an io.ReadSeeker and an io.ReaderAt, like an *os.File, chunks after the samples are
+// parsed as well and the Reader can seek to a frame.
+func NewReader[T audio.Sample](r io.Reader) (*Reader[T], error) {
+	seeker, readerAt, start, err := chunk.Start(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var form [12]byte
+	if _, err := io.ReadFull(r, form[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrNotAIFF
+		}
+		return nil, err
+	}
+	if string(form[:4]) != "FORM" {
+		return nil, ErrNotAIFF
+	}
+	aifc := string(form[8:]) == "AIFC"
+	if !aifc && string(form[8:]) != "AIFF" {
+		return nil, ErrNotAIFF
+	}
+
+	var (
+		rd         = new(Reader[T])
+		h          *header
+		offset     = start + int64(len(form))
+		dataOffset = int64(-1)
+		dataSize   int64
+	)
+chunks:
+	for {
+		id, size, err := chunk.ReadHeader(r, binary.BigEndian)
+		if err == io.EOF && dataOffset >= 0 {
+			break
+		} else if err == io.EOF {
+			return nil, ErrData
+		} else if err != nil {
+			return nil, err
+		}
+		offset += 8
+
+		if id == "SSND" {
+			// The samples start after the offset and block size, and an offset into the block.
+			var ssnd [8]byte
+			if size < 8 {
+				return nil, ErrData
+			}
+			if _, err := io.ReadFull(r, ssnd[:]); err != nil {
+				return nil, err
+			}
+			skipped := int64(binary.BigEndian.Uint32(ssnd[:]))
+			if skipped > size-8 {
+				return nil, ErrData
+			}
+			if err := chunk.Skip(r, skipped); err != nil {
+				return nil, err
+			}
+			dataOffset, dataSize = offset+8+skipped, size-8-skipped
+			if seeker == nil {
+				break chunks
+			}
+			// Continue with the chunks after the samples.
+			if _, err := seeker.Seek(chunk.Padded(size)-8-skipped, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+			offset += chunk.Padded(size)
+			continue
+		}
+
+		switch id {
+		case "COMM", "MARK", "INST":
+			body, err := chunk.ReadBody(r, id, size)
+			if errors.Is(err, chunk.ErrTooLarge) {
+				return nil, fmt.Errorf("aiff: %w", err)
+			} else if err != nil {
+				if dataOffset >= 0 {
+					// Truncated metadata after the samples.
+					break chunks
+				}
+				return nil, err
+			}
+
+			switch id {
+			case "COMM":
+				if h, err = parseHeader(body, aifc); err != nil {
+					return nil, err
+				}
+			case "MARK":
+				rd.Markers = parseMarkers(body)
+			case "INST":
+				rd.Instrument = parseInstrument(body)
+			}
+		default:
+			if err := chunk.Skip(r, chunk.Padded(size)); err != nil {
+				if dataOffset >= 0 {
+					break chunks
+				}
+				return nil, err
+			}
+		}
+		offset += chunk.Padded(size)
+	}
+
+	if h == nil {
+		return nil, ErrFormat
+	}
+	format, err := h.format()
+	if err != nil {
+		return nil, err
+	}
+	// The number of frames in the header is authoritative, the SSND chunk may be padded.
+	dataSize = min(dataSize, h.frames*int64(format.BytesPerFrame()))
+
+	var data io.Reader
+	if readerAt != nil {
+		data = io.NewSectionReader(readerAt, dataOffset, dataSize)
+	} else {
+		data = io.LimitReader(r, dataSize)
+	}
+	if rd.reader, err = pcm.NewReader[T](data, format); err != nil {
+		return nil, err
+	}
+	rd.format = format
+	rd.compression = h.compression
+	rd.sampleSize = h.sampleSize
+	rd.frames = dataSize / int64(format.BytesPerFrame())
+	return rd, nil
+}
+
+// parseHeader parses a COMM chunk, which has the compression type in AIFF-C files.
+func parseHeader(b []byte, aifc bool) (*header, error) {
+	if len(b) < 18 || aifc && len(b) < 22 {
+		return nil, ErrFormat
+	}
+	h := &header{
+		channels:    int(binary.BigEndian.Uint16(b)),
+		frames:      int64(binary.BigEndian.Uint32(b[2:])),
+		sampleSize:  int(binary.BigEndian.Uint16(b[6:])),
+		sampleRate:  extended(b[8:18]),
+		compression: CompressionNone,
+	}
+	if aifc {
+		h.compression = string(b[18:22])
+	}
+	if h.channels < 1 || h.sampleSize < 1 || h.sampleRate < 1 || h.sampleRate > math.MaxInt32 {
+		return nil, ErrFormat
+	}
+	return h, nil
+}
+
+// format returns the audio format of the samples.
+func (h *header) format() (audio.Format, error) {
+	format := audio.Format{
+		SampleRate: int(math.Round(h.sampleRate)),
+		Channels:   h.channels,
+		Encoding:   audio.Signed,
+		// Samples are stored in the most significant bits, so they are read at the size of their
+		// container.
+		Bits:      (h.sampleSize + 7) / 8 * 8,
+		ByteOrder: binary.BigEndian,
+	}
+	switch h.compression {
+	case CompressionNone, CompressionTwos:
+	case CompressionSowt:
+		format.ByteOrder = binary.LittleEndian
+	case CompressionRaw:
+		format.Encoding, format.Bits = audio.Unsigned, 8
+	case CompressionFloat32, "FL32":
+		format.Encoding, format.Bits = audio.Float, 32
+	case CompressionFloat64, "FL64":
+		format.Encoding, format.Bits = audio.Float, 64
+	case CompressionALaw, "ALAW":
+		format.Encoding, format.Bits = audio.ALaw, 8
+	case CompressionMuLaw, "ULAW":
+		format.Encoding, format.Bits = audio.MuLaw, 8
+	default:
+		return format, fmt.Errorf("aiff: unsupported compression type %q", h.compression)
+	}
+	if format.Bits <= 8 {
+		format.ByteOrder = nil
+	}
+	return format, format.Validate()
+}
+
+// Format of the samples in the file.
+func (rd *Reader[T]) Format() audio.Format {
+	return rd.format
+}
+
+// Compression type of the samples, CompressionNone for AIFF files.
+func (rd *Reader[T]) Compression() string {
+	return rd.compression
+}
+
+// SampleSize is the number of significant bits of the samples, which can be less than the bits
+// of the format.
+func (rd *Reader[T]) SampleSize() int {
+	return rd.sampleSize
+}
+
+// Frames is the number of frames in the file.
+func (rd *Reader[T]) Frames() int64 {
+	return rd.frames
+}
+
+// Duration of the samples in the file.
+func (rd *Reader[T]) Duration() time.Duration {
+	return rd.format.Duration(rd.frames)
+}
+
+func (rd *Reader[T]) String() string {
+	return fmt.Sprintf("aiff %q, %s", rd.compression, rd.format)
+}
+
+// ReadSamples reads samples converted to T.
+func (rd *Reader[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	return rd.reader.ReadSamples(samples)
+}
+
+// SeekFrame seeks to a frame, if the io.Reader can seek.
+func (rd *Reader[T]) SeekFrame(frame int64, whence int) (int64, error) {
+	if s, ok := rd.reader.(audio.FrameSeeker); ok {
+		return s.SeekFrame(frame, whence)
+	}
+	return 0, audio.ErrSeek
+}
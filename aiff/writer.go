@@ -0,0 +1,217 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/pcm"
+)
+
+// Writer writes samples to an AIFF or AIFF-C file.
+//
+// The header is written with empty sizes, which are patched when the Writer is closed.
+type Writer[T audio.Sample] struct {
+	// Metadata is written after the samples when the Writer is closed.
+	Metadata
+
+	w           io.WriteSeeker
+	format      audio.Format
+	sampleSize  int
+	compression string
+	writer      audio.Writer[T]
+
+	// start is the offset of the FORM header, commOffset the offset of the COMM chunk body and
+	// dataOffset the offset of the samples.
+	start      int64
+	commOffset int64
+	dataOffset int64
+	size       int64
+	closed     bool
+}
+
+// NewWriter writes a header for samples in the given format to w, and returns a Writer for
+// samples of type T.
+//
+// Big-endian PCM is written as an AIFF file. Little-endian PCM (sowt), floats, A-law and µ-law are
+// written as AIFF-C files. 8-bit samples are stored signed.
+//
+// The bits of PCM samples are written as the sample size, the samples are stored in the most
+// significant bits of whole bytes. So 24-bit samples in 32-bit containers are stored in 3 bytes.
+func NewWriter[T audio.Sample](w io.WriteSeeker, format audio.Format) (*Writer[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	sampleSize := format.Bits
+	var compression string
+	switch format.Encoding {
+	case audio.Signed, audio.Unsigned:
+		format.Encoding = audio.Signed
+		format.Bits = (format.Bits + 7) &^ 7
+		format.Container = 0
+		compression = CompressionNone
+		if format.Bits > 8 && format.ByteOrder == binary.LittleEndian {
+			compression = CompressionSowt
+		}
+	case audio.Float:
+		format.ByteOrder = binary.BigEndian
+		compression = CompressionFloat32
+		if format.Bits == 64 {
+			compression = CompressionFloat64
+		}
+	case audio.ALaw:
+		compression = CompressionALaw
+	case audio.MuLaw:
+		compression = CompressionMuLaw
+	default:
+		return nil, fmt.Errorf("aiff: can't write %s samples", format.Encoding)
+	}
+	if format.Bits <= 8 {
+		format.ByteOrder = nil
+	} else if compression != CompressionSowt {
+		format.ByteOrder = binary.BigEndian
+	}
+
+	start, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	wr := &Writer[T]{
+		w:           w,
+		format:      format,
+		sampleSize:  sampleSize,
+		compression: compression,
+		start:       start,
+	}
+	if wr.writer, err = pcm.NewWriter[T](w, format); err != nil {
+		return nil, err
+	}
+	if err = wr.writeHeader(); err != nil {
+		return nil, err
+	}
+	return wr, nil
+}
+
+// Format of the samples in the file.
+func (wr *Writer[T]) Format() audio.Format {
+	return wr.format
+}
+
+// SampleSize is the number of significant bits of the samples, which can be less than the bits
+// of the format.
+func (wr *Writer[T]) SampleSize() int {
+	return wr.sampleSize
+}
+
+// Compression type of the samples, CompressionNone for AIFF files.
+func (wr *Writer[T]) Compression() string {
+	return wr.compression
+}
+
+func (wr *Writer[T]) String() string {
+	return fmt.Sprintf("aiff %q, %s", wr.compression, wr.format)
+}
+
+// aifc checks if the file is an AIFF-C file.
+func (wr *Writer[T]) aifc() bool {
+	return wr.compression != CompressionNone
+}
+
+// writeHeader writes the FORM header, the COMM chunk and the SSND chunk header.
+func (wr *Writer[T]) writeHeader() error {
+	b := []byte("FORM\x00\x00\x00\x00AIFF")
+	if wr.aifc() {
+		copy(b[8:], "AIFC")
+		b = append(b, "FVER\x00\x00\x00\x04"...)
+		b = binary.BigEndian.AppendUint32(b, aifcVersion)
+	}
+
+	f := wr.format
+	body := binary.BigEndian.AppendUint16(nil, uint16(f.Channels))
+	body = binary.BigEndian.AppendUint32(body, 0) // frames
+	body = binary.BigEndian.AppendUint16(body, uint16(wr.sampleSize))
+	body = appendExtended(body, float64(f.SampleRate))
+	if wr.aifc() {
+		body = append(body, wr.compression...)
+		body = appendPstring(body, compressionNames[wr.compression])
+	}
+	b = append(b, "COMM"...)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(body)))
+	wr.commOffset = wr.start + int64(len(b))
+	b = append(b, body...)
+
+	// The SSND chunk has no offset and block size.
+	b = append(b, "SSND\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"...)
+	if _, err := wr.w.Write(b); err != nil {
+		return err
+	}
+	wr.dataOffset = wr.start + int64(len(b))
+	return nil
+}
+
+// WriteSamples writes samples converted to the format of the file.
+func (wr *Writer[T]) WriteSamples(samples audio.Samples[T]) (int, error) {
+	if wr.closed {
+		return 0, ErrClosed
+	}
+	if size := wr.size + int64(len(samples)*wr.format.BytesPerSample()); size > math.MaxUint32-(wr.dataOffset-wr.start) {
+		return 0, ErrTooLarge
+	}
+	n, err := wr.writer.WriteSamples(samples)
+	wr.size += int64(n * wr.format.BytesPerSample())
+	return n, err
+}
+
+// Frames is the number of frames written.
+func (wr *Writer[T]) Frames() int64 {
+	return wr.size / int64(wr.format.BytesPerFrame())
+}
+
+// Close writes the metadata and patches the sizes in the header. It doesn't close the
+// io.WriteSeeker.
+func (wr *Writer[T]) Close() error {
+	if wr.closed {
+		return nil
+	}
+	wr.closed = true
+
+	end := wr.dataOffset + wr.size
+	if _, err := wr.w.Seek(end, io.SeekStart); err != nil {
+		return err
+	}
+	if wr.size&1 == 1 {
+		if _, err := wr.w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+	if err := wr.Metadata.encode(wr.w); err != nil {
+		return err
+	}
+	end, err := wr.w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	if err = wr.patch(wr.start+4, end-wr.start-8); err != nil {
+		return err
+	}
+	if err = wr.patch(wr.commOffset+2, wr.Frames()); err != nil {
+		return err
+	}
+	if err = wr.patch(wr.dataOffset-12, wr.size+8); err != nil {
+		return err
+	}
+	_, err = wr.w.Seek(end, io.SeekStart)
+	return err
+}
+
+// patch writes a size at an offset.
+func (wr *Writer[T]) patch(offset, size int64) error {
+	if _, err := wr.w.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := wr.w.Write(binary.BigEndian.AppendUint32(nil, uint32(size)))
+	return err
+}
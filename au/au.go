@@ -0,0 +1,105 @@
+// Package au reads and writes Sun/NeXT .au files.
+//
+// Supported encodings are 8, 16, 24 and 32-bit linear PCM, 32 and 64-bit floats, A-law and µ-law.
+// Files with the little-endian "dns." magic of some older tools can be read as well.
+package au
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/BeatGlow/audio"
+)
+
+var (
+	ErrNotAU  = errors.New("au: not a .au file")
+	ErrHeader = errors.New("au: invalid header")
+	ErrClosed = errors.New("au: writer is closed")
+)
+
+// UnknownSize is the data size of files written to a stream.
+const UnknownSize = 0xffffffff
+
+// headerSize is the size of the header without the annotation.
+const headerSize = 24
+
+// Encoding is the encoding field of the header.
+type Encoding uint32
+
+// Encodings.
+const (
+	EncodingMuLaw    Encoding = 1
+	EncodingLinear8  Encoding = 2
+	EncodingLinear16 Encoding = 3
+	EncodingLinear24 Encoding = 4
+	EncodingLinear32 Encoding = 5
+	EncodingFloat    Encoding = 6
+	EncodingDouble   Encoding = 7
+	EncodingALaw     Encoding = 27
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingMuLaw:
+		return "µ-law"
+	case EncodingLinear8, EncodingLinear16, EncodingLinear24, EncodingLinear32:
+		return fmt.Sprintf("%d-bit linear", (e-EncodingLinear8+1)*8)
+	case EncodingFloat:
+		return "float"
+	case EncodingDouble:
+		return "double"
+	case EncodingALaw:
+		return "A-law"
+	default:
+		return fmt.Sprintf("encoding %d", uint32(e))
+	}
+}
+
+// format returns the audio format of the encoding.
+func (e Encoding) format(sampleRate, channels int, order binary.ByteOrder) (audio.Format, error) {
+	format := audio.Format{
+		SampleRate: sampleRate,
+		Channels:   channels,
+		Encoding:   audio.Signed,
+		ByteOrder:  order,
+	}
+	switch e {
+	case EncodingMuLaw:
+		format.Encoding, format.Bits = audio.MuLaw, 8
+	case EncodingALaw:
+		format.Encoding, format.Bits = audio.ALaw, 8
+	case EncodingLinear8, EncodingLinear16, EncodingLinear24, EncodingLinear32:
+		format.Bits = int(e-EncodingLinear8+1) * 8
+	case EncodingFloat:
+		format.Encoding, format.Bits = audio.Float, 32
+	case EncodingDouble:
+		format.Encoding, format.Bits = audio.Float, 64
+	default:
+		return format, fmt.Errorf("au: unsupported %s", e)
+	}
+	if format.Bits <= 8 {
+		format.ByteOrder = nil
+	}
+	return format, format.Validate()
+}
+
+// encodingOf returns the Encoding for a format.
+func encodingOf(format audio.Format) (Encoding, error) {
+	switch format.Encoding {
+	case audio.Signed, audio.Unsigned:
+		if bits := format.BitsPerSample(); bits <= 32 {
+			return EncodingLinear8 + Encoding(bits/8-1), nil
+		}
+	case audio.Float:
+		if format.Bits == 64 {
+			return EncodingDouble, nil
+		}
+		return EncodingFloat, nil
+	case audio.ALaw:
+		return EncodingALaw, nil
+	case audio.MuLaw:
+		return EncodingMuLaw, nil
+	}
+	return 0, fmt.Errorf("au: can't write %s", format)
+}
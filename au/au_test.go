@@ -0,0 +1,131 @@
+package au
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/audiotest"
+)
+
+func TestRoundTrip(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Format   audio.Format
+		Encoding Encoding
+	}{
+		{"u8", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.Unsigned, Bits: 8}, EncodingLinear8},
+		{"s16le", audio.Format{SampleRate: 44100, Channels: 2, Encoding: audio.Signed, Bits: 16, ByteOrder: binary.LittleEndian}, EncodingLinear16},
+		{"s24", audio.Format{SampleRate: 48000, Channels: 2, Encoding: audio.Signed, Bits: 24, ByteOrder: binary.BigEndian}, EncodingLinear24},
+		{"s32", audio.Format{SampleRate: 48000, Channels: 1, Encoding: audio.Signed, Bits: 32, ByteOrder: binary.BigEndian}, EncodingLinear32},
+		{"f32", audio.Format{SampleRate: 48000, Channels: 1, Encoding: audio.Float, Bits: 32, ByteOrder: binary.LittleEndian}, EncodingFloat},
+		{"f64", audio.Format{SampleRate: 48000, Channels: 1, Encoding: audio.Float, Bits: 64, ByteOrder: binary.BigEndian}, EncodingDouble},
+		{"alaw", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.ALaw, Bits: 8}, EncodingALaw},
+		{"ulaw", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.MuLaw, Bits: 8}, EncodingMuLaw},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			samples := audiotest.Ramp(test.Format.Channels, 7)
+			data := audiotest.Write(it, func(f *audiotest.File) (*Writer[int16], error) {
+				return NewWriterAnnotation[int16](f, test.Format, "test")
+			}, samples, ErrClosed)
+
+			r, err := NewReader[int16](bytes.NewReader(data))
+			if err != nil {
+				it.Fatal(err)
+			}
+			if r.Encoding() != test.Encoding {
+				it.Errorf("expected %s, got %s", test.Encoding, r.Encoding())
+			}
+			if r.Annotation != "test" {
+				it.Errorf("expected annotation %q, got %q", "test", r.Annotation)
+			}
+			if r.Frames() != 7 {
+				it.Errorf("expected 7 frames, got %d", r.Frames())
+			}
+			audiotest.Near(it, audiotest.ReadAll(it, r), samples)
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	// Without seeking, the size remains unknown and samples are read until the end.
+	var buf bytes.Buffer
+	format := audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.Signed, Bits: 16, ByteOrder: binary.BigEndian}
+	w, err := NewWriter[int16](&buf, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.WriteSamples(audio.Samples[int16]{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if size := binary.BigEndian.Uint32(buf.Bytes()[8:]); size != UnknownSize {
+		t.Errorf("expected an unknown size, got %d", size)
+	}
+
+	r, err := NewReader[int16](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Frames() != -1 || r.Duration() != -1 {
+		t.Errorf("expected an unknown length, got %d frames", r.Frames())
+	}
+	if got := audiotest.ReadAll(t, r); !reflect.DeepEqual(got, audio.Samples[int16]{1, 2, 3}) {
+		t.Errorf("unexpected samples %v", got)
+	}
+}
+
+func TestReader(t *testing.T) {
+	// A little-endian file from older tools.
+	data := []byte("dns.")
+	for _, v := range []uint32{28, 4, uint32(EncodingLinear16), 8000, 1, 0} {
+		data = binary.LittleEndian.AppendUint32(data, v)
+	}
+	data = append(data, 0x00, 0x40, 0x00, 0xc0, 0xff)
+
+	r, err := NewReader[int16](bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := r.String(), "au 16-bit linear, "+r.Format().String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if got := audiotest.ReadAll(t, r); !reflect.DeepEqual(got, audio.Samples[int16]{0x4000, -0x4000}) {
+		t.Errorf("unexpected samples %v", got)
+	}
+
+	// The file can start after the beginning of the io.Reader.
+	embedded := bytes.NewReader(append([]byte("junk"), data...))
+	if _, err = embedded.Seek(4, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if r, err = NewReader[int16](embedded); err != nil {
+		t.Fatal(err)
+	}
+	if got := audiotest.ReadAll(t, r); !reflect.DeepEqual(got, audio.Samples[int16]{0x4000, -0x4000}) {
+		t.Errorf("unexpected samples in an embedded file %v", got)
+	}
+
+	testCases := []struct {
+		Name string
+		Data []byte
+		Err  error
+	}{
+		{"empty", nil, ErrNotAU},
+		{"not au", []byte("RIFF\x04\x00\x00\x00WAVEfmt \x00\x00\x00\x00\x00\x00\x00\x00"), ErrNotAU},
+		{"offset", append([]byte(".snd\x00\x00\x00\x10"), make([]byte, 16)...), ErrHeader},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			if _, err := NewReader[int16](bytes.NewReader(test.Data)); err != test.Err {
+				it.Errorf("expected %v, got %v", test.Err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package au
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/chunk"
+	"github.com/BeatGlow/audio/internal/pcm"
+)
+
+// Reader reads samples from a .au file.
+type Reader[T audio.Sample] struct {
+	// Annotation is the text after the header.
+	Annotation string
+
+	format   audio.Format
+	encoding Encoding
+	frames   int64
+	reader   audio.FormatReader[T]
+}
+
+// NewReader parses the header of a .au file read from r, and returns a Reader for its samples
+// converted to T.
+//
+// Files written to a stream have an unknown size, their samples are read until the end of r.
+func NewReader[T audio.Sample](r io.Reader) (*Reader[T], error) {
+	_, readerAt, start, err := chunk.Start(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrNotAU
+		}
+		return nil, err
+	}
+	var order binary.ByteOrder
+	switch string(header[:4]) {
+	case ".snd":
+		order = binary.BigEndian
+	case "dns.":
+		order = binary.LittleEndian
+	default:
+		return nil, ErrNotAU
+	}
+
+	var (
+		offset     = int64(order.Uint32(header[4:]))
+		size       = int64(order.Uint32(header[8:]))
+		encoding   = Encoding(order.Uint32(header[12:]))
+		sampleRate = order.Uint32(header[16:])
+		channels   = order.Uint32(header[20:])
+	)
+	if offset < headerSize || offset > 1<<24 || sampleRate > math.MaxInt32 || channels > math.MaxInt32 {
+		return nil, ErrHeader
+	}
+	format, err := encoding.format(int(sampleRate), int(channels), order)
+	if err != nil {
+		return nil, err
+	}
+
+	annotation := make([]byte, offset-headerSize)
+	if _, err = io.ReadFull(r, annotation); err != nil {
+		return nil, err
+	}
+	if i := bytes.IndexByte(annotation, 0); i >= 0 {
+		annotation = annotation[:i]
+	}
+
+	data := r
+	if size != UnknownSize {
+		data = io.LimitReader(r, size)
+		if readerAt != nil {
+			data = io.NewSectionReader(readerAt, start+offset, size)
+		}
+	}
+	rd := &Reader[T]{
+		Annotation: string(annotation),
+		format:     format,
+		encoding:   encoding,
+		frames:     -1,
+	}
+	if size != UnknownSize {
+		rd.frames = size / int64(format.BytesPerFrame())
+	}
+	if rd.reader, err = pcm.NewReader[T](data, format); err != nil {
+		return nil, err
+	}
+	return rd, nil
+}
+
+// Format of the samples in the file.
+func (rd *Reader[T]) Format() audio.Format {
+	return rd.format
+}
+
+// Encoding of the samples in the header.
+func (rd *Reader[T]) Encoding() Encoding {
+	return rd.encoding
+}
+
+// Frames is the number of frames in the file, or -1 if the size is unknown.
+func (rd *Reader[T]) Frames() int64 {
+	return rd.frames
+}
+
+// Duration of the samples in the file, or -1 if the size is unknown.
+func (rd *Reader[T]) Duration() time.Duration {
+	if rd.frames < 0 {
+		return -1
+	}
+	return rd.format.Duration(rd.frames)
+}
+
+func (rd *Reader[T]) String() string {
+	return fmt.Sprintf("au %s, %s", rd.encoding, rd.format)
+}
+
+// ReadSamples reads samples converted to T.
+func (rd *Reader[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	return rd.reader.ReadSamples(samples)
+}
+
+// SeekFrame seeks to a frame, if the io.Reader can seek.
+func (rd *Reader[T]) SeekFrame(frame int64, whence int) (int64, error) {
+	if s, ok := rd.reader.(audio.FrameSeeker); ok {
+		return s.SeekFrame(frame, whence)
+	}
+	return 0, audio.ErrSeek
+}
@@ -0,0 +1,119 @@
+package au
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/pcm"
+)
+
+// Writer writes samples to a .au file.
+//
+// If the io.Writer can seek, the data size in the header is patched when the Writer is closed.
+// Otherwise it remains UnknownSize, which readers handle by reading until the end of the file.
+type Writer[T audio.Sample] struct {
+	w      io.Writer
+	format audio.Format
+	writer audio.Writer[T]
+
+	// start is the offset of the header, or -1 if w can't seek.
+	start  int64
+	size   int64
+	closed bool
+}
+
+// NewWriter writes a header for samples in the given format to w, and returns a Writer for
+// samples of type T. Linear PCM is stored signed and big-endian.
+func NewWriter[T audio.Sample](w io.Writer, format audio.Format) (*Writer[T], error) {
+	return NewWriterAnnotation[T](w, format, "")
+}
+
+// NewWriterAnnotation is like NewWriter, with an annotation in the header.
+func NewWriterAnnotation[T audio.Sample](w io.Writer, format audio.Format, annotation string) (*Writer[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	encoding, err := encodingOf(format)
+	if err != nil {
+		return nil, err
+	}
+	if format, err = encoding.format(format.SampleRate, format.Channels, binary.BigEndian); err != nil {
+		return nil, err
+	}
+
+	wr := &Writer[T]{
+		w:      w,
+		format: format,
+		start:  -1,
+	}
+	if s, ok := w.(io.Seeker); ok {
+		if start, err := s.Seek(0, io.SeekCurrent); err == nil {
+			wr.start = start
+		}
+	}
+	if wr.writer, err = pcm.NewWriter[T](w, format); err != nil {
+		return nil, err
+	}
+
+	// The annotation is terminated by at least one NUL, and padded to a multiple of 8 bytes.
+	size := (len(annotation) + 1 + 7) &^ 7
+	b := []byte(".snd")
+	b = binary.BigEndian.AppendUint32(b, uint32(headerSize+size))
+	b = binary.BigEndian.AppendUint32(b, UnknownSize)
+	b = binary.BigEndian.AppendUint32(b, uint32(encoding))
+	b = binary.BigEndian.AppendUint32(b, uint32(format.SampleRate))
+	b = binary.BigEndian.AppendUint32(b, uint32(format.Channels))
+	b = append(b, annotation...)
+	b = append(b, make([]byte, size-len(annotation))...)
+	if _, err = w.Write(b); err != nil {
+		return nil, err
+	}
+	return wr, nil
+}
+
+// Format of the samples in the file.
+func (wr *Writer[T]) Format() audio.Format {
+	return wr.format
+}
+
+func (wr *Writer[T]) String() string {
+	return fmt.Sprintf("au %s", wr.format)
+}
+
+// WriteSamples writes samples converted to the format of the file.
+func (wr *Writer[T]) WriteSamples(samples audio.Samples[T]) (int, error) {
+	if wr.closed {
+		return 0, ErrClosed
+	}
+	n, err := wr.writer.WriteSamples(samples)
+	wr.size += int64(n * wr.format.BytesPerSample())
+	return n, err
+}
+
+// Close patches the data size in the header if the io.Writer can seek, and the size fits. It
+// doesn't close the io.Writer.
+func (wr *Writer[T]) Close() error {
+	if wr.closed {
+		return nil
+	}
+	wr.closed = true
+	if wr.start < 0 || wr.size >= UnknownSize {
+		return nil
+	}
+
+	s := wr.w.(io.Seeker)
+	end, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = s.Seek(wr.start+8, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err = wr.w.Write(binary.BigEndian.AppendUint32(nil, uint32(wr.size))); err != nil {
+		return err
+	}
+	_, err = s.Seek(end, io.SeekStart)
+	return err
+}
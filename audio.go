@@ -1,11 +1,242 @@
 // Package audio ...
 package audio
 
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrChannels   = errors.New("audio: need more than 0 channels")
+	ErrSampleRate = errors.New("audio: need a positive sample rate")
+	ErrBits       = errors.New("audio: invalid number of bits per sample")
+	ErrByteOrder  = errors.New("audio: multi-byte samples need a byte order")
+	ErrEncoding   = errors.New("audio: unsupported sample encoding")
+)
+
+// Encoding is the encoding of a single sample.
+type Encoding int
+
+const (
+	UnknownEncoding Encoding = iota
+
+	// Signed is two's complement linear PCM.
+	Signed
+
+	// Unsigned is linear PCM biased around the midpoint of its range.
+	Unsigned
+
+	// Float is IEEE 754 floating point PCM.
+	Float
+
+	// ALaw is 8-bit companded PCM according to ITU-T G.711 A-law, see the codec/g711 package.
+	ALaw
+
+	// MuLaw is 8-bit companded PCM according to ITU-T G.711 µ-law, see the codec/g711 package.
+	MuLaw
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case Signed:
+		return "signed"
+	case Unsigned:
+		return "unsigned"
+	case Float:
+		return "float"
+	case ALaw:
+		return "A-law"
+	case MuLaw:
+		return "µ-law"
+	default:
+		return fmt.Sprintf("unknown encoding %d", e)
+	}
+}
+
 // Format describes the audio format.
-type Format interface {
-	// Channels is the number of channels.
-	Channels() int
+type Format struct {
+	// SampleRate is the number of frames per second.
+	SampleRate int
+
+	// Channels is the number of channels per frame.
+	Channels int
+
+	// Encoding of each sample.
+	Encoding Encoding
+
+	// Bits is the number of significant bits per sample, also known as the bit depth.
+	Bits int
+
+	// Container is the number of bits a sample occupies in the stream. If Container
+	// is zero, Bits rounded up to whole bytes is used. If Container is larger than
+	// Bits, the significant bits are stored in the least significant bits of the
+	// container (like ALSA's S24_LE).
+	Container int
+
+	// ByteOrder of multi-byte samples in the stream.
+	ByteOrder binary.ByteOrder
+}
+
+// FormatOf returns the Format for samples of type T.
+func FormatOf[T Sample](sampleRate, channels int, order binary.ByteOrder) Format {
+	bits := Samples[T]{}.BitsPerSample()
+	return Format{
+		SampleRate: sampleRate,
+		Channels:   channels,
+		Encoding:   encodingOf[T](),
+		Bits:       bits,
+		ByteOrder:  order,
+	}
+}
+
+// BitsPerSample is the number of bits required to store one sample.
+func (f Format) BitsPerSample() int {
+	if f.Container > 0 {
+		return f.Container
+	}
+	return (f.Bits + 7) &^ 7
+}
+
+// BytesPerSample is the number of bytes required to store one sample.
+func (f Format) BytesPerSample() int {
+	return f.BitsPerSample() / 8
+}
+
+// BytesPerFrame is the number of bytes required to store one sample for each channel.
+func (f Format) BytesPerFrame() int {
+	return f.BytesPerSample() * f.Channels
+}
+
+// Frames returns the number of frames in d, rounded to the nearest frame.
+func (f Format) Frames(d time.Duration) int {
+	if d < 0 {
+		return -f.Frames(-d)
+	}
+	// Split whole seconds, so long durations don't overflow.
+	var (
+		rate = int64(f.SampleRate)
+		sec  = int64(d / time.Second)
+		rem  = int64(d % time.Second)
+	)
+	return int(sec*rate + (rem*rate+int64(time.Second)/2)/int64(time.Second))
+}
+
+// Samples returns the number of samples in d, which is Frames(d) for each channel.
+func (f Format) Samples(d time.Duration) int {
+	return f.Frames(d) * f.Channels
+}
+
+// Duration returns the duration of frames, rounded to the nearest nanosecond.
+func (f Format) Duration(frames int64) time.Duration {
+	if f.SampleRate < 1 {
+		return 0
+	}
+	if frames < 0 {
+		return -f.Duration(-frames)
+	}
+	rate := int64(f.SampleRate)
+	return time.Duration(frames/rate)*time.Second +
+		time.Duration(((frames%rate)*int64(time.Second)+rate/2)/rate)
+}
+
+// Validate checks if the format is complete and consistent.
+func (f Format) Validate() error {
+	if f.Channels < 1 {
+		return ErrChannels
+	}
+	if f.SampleRate < 1 {
+		return ErrSampleRate
+	}
+	switch f.Encoding {
+	case Signed, Unsigned:
+		if f.Bits < 1 || f.Bits > 64 {
+			return ErrBits
+		}
+	case Float:
+		if f.Bits != 32 && f.Bits != 64 {
+			return ErrBits
+		}
+	case ALaw, MuLaw:
+		if f.Bits != 8 {
+			return ErrBits
+		}
+	default:
+		return ErrEncoding
+	}
+	if container := f.BitsPerSample(); container < f.Bits || container > 64 || container%8 != 0 {
+		return ErrBits
+	} else if container > 8 && f.ByteOrder == nil {
+		return ErrByteOrder
+	}
+	return nil
+}
+
+func (f Format) String() string {
+	var kind string
+	switch f.Encoding {
+	case Signed:
+		kind = "s"
+	case Unsigned:
+		kind = "u"
+	case Float:
+		kind = "f"
+	case ALaw:
+		kind = "alaw"
+	case MuLaw:
+		kind = "ulaw"
+	default:
+		kind = "?"
+	}
+	if f.Encoding != ALaw && f.Encoding != MuLaw {
+		kind += fmt.Sprint(f.Bits)
+	}
+	if f.Container > 0 && f.Container != f.Bits {
+		kind += fmt.Sprintf("in%d", f.Container)
+	}
+	if f.BitsPerSample() > 8 {
+		switch f.ByteOrder {
+		case binary.LittleEndian:
+			kind += "le"
+		case binary.BigEndian:
+			kind += "be"
+		}
+	}
+	return fmt.Sprintf("%s, %d channels, %d Hz", kind, f.Channels, f.SampleRate)
+}
+
+// matches checks if samples of type T can be decoded from the format without conversion.
+func matches[T Sample](f Format) bool {
+	var zero Samples[T]
+	return f.Encoding == encodingOf[T]() &&
+		f.Bits == zero.BitsPerSample() &&
+		f.BitsPerSample() == zero.BitsPerSample()
+}
+
+// encodingOf returns the Encoding for samples of type T.
+func encodingOf[T Sample]() Encoding {
+	var zero T
+	switch any(zero).(type) {
+	case int, int8, int16, int32, int64:
+		return Signed
+	case uint, uint8, uint16, uint32, uint64:
+		return Unsigned
+	case float32, float64:
+		return Float
+	default:
+		return UnknownEncoding
+	}
+}
+
+// FormatReader is a Reader that knows the Format of the samples it produces.
+type FormatReader[T Sample] interface {
+	Reader[T]
+	Format() Format
+}
 
-	// BitsPerSample is the number of bits required to store one sample.
-	BitsPerSample() int
+// FormatWriter is a Writer that knows the Format of the samples it consumes.
+type FormatWriter[T Sample] interface {
+	Writer[T]
+	Format() Format
 }
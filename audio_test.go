@@ -0,0 +1,100 @@
+package audio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/BeatGlow/audio"
+)
+
+func TestFormatValidate(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Format audio.Format
+		Want   error
+	}{
+		{"s16le", audio.FormatOf[int16](44100, 2, binary.LittleEndian), nil},
+		{"u8", audio.FormatOf[uint8](8000, 1, nil), nil},
+		{"f32be", audio.FormatOf[float32](48000, 6, binary.BigEndian), nil},
+		{"s24in32le", audio.Format{SampleRate: 48000, Channels: 2, Encoding: audio.Signed, Bits: 24, Container: 32, ByteOrder: binary.LittleEndian}, nil},
+		{"no channels", audio.FormatOf[int16](44100, 0, binary.LittleEndian), audio.ErrChannels},
+		{"no rate", audio.FormatOf[int16](0, 2, binary.LittleEndian), audio.ErrSampleRate},
+		{"no order", audio.FormatOf[int16](44100, 2, nil), audio.ErrByteOrder},
+		{"no encoding", audio.Format{SampleRate: 44100, Channels: 2, Bits: 16}, audio.ErrEncoding},
+		{"float16", audio.Format{SampleRate: 44100, Channels: 2, Encoding: audio.Float, Bits: 16}, audio.ErrBits},
+		{"alaw", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.ALaw, Bits: 8}, nil},
+		{"ulaw16", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.MuLaw, Bits: 16, ByteOrder: binary.LittleEndian}, audio.ErrBits},
+		{"small container", audio.Format{SampleRate: 44100, Channels: 2, Encoding: audio.Signed, Bits: 24, Container: 16}, audio.ErrBits},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			if err := test.Format.Validate(); err != test.Want {
+				it.Fatalf("expected %s to return error %v, got %v", test.Format, test.Want, err)
+			}
+		})
+	}
+}
+
+func TestFormatSizes(t *testing.T) {
+	f := audio.Format{SampleRate: 48000, Channels: 2, Encoding: audio.Signed, Bits: 24, ByteOrder: binary.LittleEndian}
+	if v := f.BytesPerFrame(); v != 6 {
+		t.Errorf("expected %s to use 6 bytes per frame, got %d", f, v)
+	}
+	f.Container = 32
+	if v := f.BytesPerFrame(); v != 8 {
+		t.Errorf("expected %s to use 8 bytes per frame, got %d", f, v)
+	}
+	if v := f.String(); v != "s24in32le, 2 channels, 48000 Hz" {
+		t.Errorf("unexpected format description %q", v)
+	}
+}
+
+func TestNewReaderFormat(t *testing.T) {
+	format := audio.FormatOf[int16](8000, 1, binary.LittleEndian)
+	r, err := audio.NewReader[int16](bytes.NewBuffer([]byte{0x01, 0x00}), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := r.Format(); v != format {
+		t.Errorf("expected reader format %s, got %s", format, v)
+	}
+
+	if _, err = audio.NewReader[int32](bytes.NewBuffer(nil), format); err == nil {
+		t.Errorf("expected reading %s as int32 to fail", format)
+	}
+	if _, err = audio.NewWriter[int16](new(bytes.Buffer), audio.Format{}); err == nil {
+		t.Error("expected writer with empty format to fail")
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	f := audio.FormatOf[int16](44100, 2, binary.LittleEndian)
+	testCases := []struct {
+		Duration time.Duration
+		Frames   int
+	}{
+		{0, 0},
+		{time.Second, 44100},
+		{10 * time.Millisecond, 441},
+		{-10 * time.Millisecond, -441},
+		{time.Second / 44100, 1},
+		{72 * time.Hour, 72 * 3600 * 44100},
+	}
+	for _, test := range testCases {
+		if v := f.Frames(test.Duration); v != test.Frames {
+			t.Errorf("expected %s to be %d frames, got %d", test.Duration, test.Frames, v)
+		}
+		if v := f.Samples(test.Duration); v != 2*test.Frames {
+			t.Errorf("expected %s to be %d samples, got %d", test.Duration, 2*test.Frames, v)
+		}
+		if v := f.Frames(f.Duration(int64(test.Frames))); v != test.Frames {
+			t.Errorf("expected %d frames to round trip, got %d", test.Frames, v)
+		}
+	}
+	if v := f.Duration(22050); v != 500*time.Millisecond {
+		t.Errorf("expected 22050 frames to be 500ms, got %s", v)
+	}
+}
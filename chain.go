@@ -0,0 +1,134 @@
+package audio
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Processor processes blocks of interleaved samples in place.
+type Processor[T Sample] interface {
+	// Process samples, which always contain whole frames.
+	Process(Samples[T]) error
+}
+
+// ProcessorFunc is an adapter to allow the use of ordinary functions as Processor.
+type ProcessorFunc[T Sample] func(Samples[T]) error
+
+// Process calls f(samples).
+func (f ProcessorFunc[T]) Process(samples Samples[T]) error {
+	return f(samples)
+}
+
+// Stage creates the next stage of a Chain, by wrapping the Reader of the previous stage.
+//
+// If the returned Reader is a FormatReader, its Format is used by the following stages.
+type Stage[T Sample] func(Reader[T], Format) (Reader[T], error)
+
+// Chain is a processing pipeline of stages, which reads from the last stage.
+//
+// Errors that occur while building the chain are returned by Err and by ReadSamples.
+type Chain[T Sample] struct {
+	reader Reader[T]
+	format Format
+	stages []string
+	err    error
+}
+
+// NewChain starts a processing pipeline reading samples in the given format from r.
+func NewChain[T Sample](r Reader[T], format Format) *Chain[T] {
+	return &Chain[T]{
+		reader: r,
+		format: format,
+		err:    format.Validate(),
+	}
+}
+
+// Then adds a stage that wraps the Reader of the previous stage, such as a filter.Delay.
+func (c *Chain[T]) Then(stage Stage[T]) *Chain[T] {
+	if c.err != nil {
+		return c
+	}
+
+	r, err := stage(c.reader, c.format)
+	if err != nil {
+		c.err = err
+		return c
+	}
+	if fr, ok := r.(FormatReader[T]); ok {
+		c.format = fr.Format()
+	}
+	c.reader = r
+	c.stages = append(c.stages, describe(r))
+	return c
+}
+
+// Process adds a stage that processes the samples read from the previous stage.
+func (c *Chain[T]) Process(p Processor[T]) *Chain[T] {
+	if c.err != nil {
+		return c
+	}
+
+	c.reader = &processReader[T]{
+		Reader:    c.reader,
+		Processor: p,
+	}
+	c.stages = append(c.stages, describe(p))
+	return c
+}
+
+// Err returns the first error that occurred while building the chain.
+func (c *Chain[T]) Err() error {
+	return c.err
+}
+
+// Format of the samples produced by the last stage.
+func (c *Chain[T]) Format() Format {
+	return c.format
+}
+
+// ReadSamples reads whole frames through all stages.
+//
+// Processors only see the samples that were read, also if the previous stage returned an error.
+func (c *Chain[T]) ReadSamples(samples Samples[T]) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	// Make sure all stages see whole frames.
+	samples = samples[:len(samples)-len(samples)%c.format.Channels]
+	if len(samples) == 0 {
+		return 0, io.ErrShortBuffer
+	}
+	return c.reader.ReadSamples(samples)
+}
+
+func (c *Chain[T]) String() string {
+	if len(c.stages) == 0 {
+		return "passthrough"
+	}
+	return strings.Join(c.stages, " → ")
+}
+
+type processReader[T Sample] struct {
+	Reader[T]
+	Processor[T]
+}
+
+func (r *processReader[T]) ReadSamples(samples Samples[T]) (int, error) {
+	n, err := r.Reader.ReadSamples(samples)
+	if n > 0 {
+		if perr := r.Processor.Process(samples[:n]); perr != nil {
+			return n, perr
+		}
+	}
+	return n, err
+}
+
+// describe returns a description of a stage.
+func describe(v any) string {
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", v)
+}
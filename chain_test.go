@@ -0,0 +1,109 @@
+package audio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/filter"
+)
+
+func TestChain(t *testing.T) {
+	var (
+		format = audio.FormatOf[int16](8000, 2, binary.LittleEndian)
+		test   = make([]byte, 64*format.BytesPerFrame())
+	)
+	for i := 0; i < len(test)/2; i++ {
+		binary.LittleEndian.PutUint16(test[i*2:], uint16(i+1))
+	}
+
+	r, err := audio.NewReader[int16](bytes.NewReader(test), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		processed int
+		chain     = audio.NewChain[int16](r, format).
+				Then(func(r audio.Reader[int16], format audio.Format) (audio.Reader[int16], error) {
+				return filter.NewDelay(r, format, time.Millisecond)
+			}).
+			Process(audio.ProcessorFunc[int16](func(samples audio.Samples[int16]) error {
+				if len(samples)%format.Channels != 0 {
+					t.Errorf("expected whole frames, got %d samples", len(samples))
+				}
+				processed += len(samples)
+				for i := range samples {
+					samples[i] *= 2
+				}
+				return nil
+			}))
+	)
+	if err = chain.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if v := chain.Format(); v != format {
+		t.Errorf("expected format %s, got %s", format, v)
+	}
+	if v := chain.String(); v != "delay 1ms → audio.ProcessorFunc[int16]" {
+		t.Errorf("unexpected chain description %q", v)
+	}
+
+	// 7 samples only fit 3 frames, the delay line holds 8 frames.
+	samples := make(audio.Samples[int16], 7)
+	n, err := chain.ReadSamples(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 {
+		t.Fatalf("expected 6 samples, got %d", n)
+	}
+	for i, v := range samples[:n] {
+		if v != 0 {
+			t.Errorf("expected delayed sample %d to be 0, got %d", i, v)
+		}
+	}
+
+	samples = make(audio.Samples[int16], 32)
+	for {
+		if _, err = chain.ReadSamples(samples); err != nil {
+			break
+		}
+	}
+	if err != io.EOF {
+		t.Fatalf("expected error %q, got %q", io.EOF, err)
+	}
+	if processed == 0 {
+		t.Fatal("expected the processor to be called")
+	}
+}
+
+func TestChainErrors(t *testing.T) {
+	var (
+		format  = audio.FormatOf[float32](8000, 1, binary.LittleEndian)
+		r, _    = audio.NewReader[float32](bytes.NewReader(make([]byte, 64)), format)
+		errTest = errors.New("test")
+	)
+
+	chain := audio.NewChain[float32](r, format).
+		Then(func(r audio.Reader[float32], format audio.Format) (audio.Reader[float32], error) {
+			return nil, errTest
+		})
+	if _, err := chain.ReadSamples(make(audio.Samples[float32], 4)); err != errTest {
+		t.Fatalf("expected stage error %q, got %q", errTest, err)
+	}
+
+	chain = audio.NewChain[float32](r, format).
+		Process(audio.ProcessorFunc[float32](func(audio.Samples[float32]) error {
+			return errTest
+		}))
+	if n, err := chain.ReadSamples(make(audio.Samples[float32], 4)); err != errTest {
+		t.Fatalf("expected processor error %q, got %q", errTest, err)
+	} else if n != 4 {
+		t.Fatalf("expected 4 samples, got %d", n)
+	}
+}
@@ -0,0 +1,153 @@
+// Package adpcm implements the IMA and Microsoft ADPCM codecs, with the block layouts used in WAV
+// files.
+//
+// Both codecs encode 16-bit samples as 4-bit differences, in blocks that start with a header to
+// restart the decoder. Blocks of multiple channels contain interleaved differences.
+package adpcm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/BeatGlow/audio"
+)
+
+var (
+	ErrChannels   = errors.New("adpcm: need more than 0 channels")
+	ErrBlockAlign = errors.New("adpcm: block size too small for the channels")
+	ErrBlock      = errors.New("adpcm: invalid block")
+	ErrShort      = errors.New("adpcm: buffer too short for a block")
+)
+
+// Codec encodes and decodes blocks of interleaved samples.
+type Codec interface {
+	fmt.Stringer
+
+	// Channels is the number of interleaved channels.
+	Channels() int
+
+	// BlockAlign is the size of an encoded block in bytes.
+	BlockAlign() int
+
+	// FramesPerBlock is the number of frames in a block.
+	FramesPerBlock() int
+
+	// DecodeBlock decodes a block into dst, and returns the number of samples decoded. The last
+	// block of a stream may be shorter than BlockAlign.
+	DecodeBlock(dst audio.Samples[int16], block []byte) (int, error)
+
+	// EncodeBlock encodes up to FramesPerBlock frames of src into a block of BlockAlign bytes in
+	// dst, missing frames are silent.
+	EncodeBlock(dst []byte, src audio.Samples[int16]) error
+}
+
+// Reader decodes blocks read from an io.Reader.
+type Reader struct {
+	r       io.Reader
+	codec   Codec
+	block   []byte
+	samples audio.Samples[int16]
+	pending audio.Samples[int16]
+}
+
+// NewReader decodes blocks read from r with the codec.
+func NewReader(r io.Reader, codec Codec) *Reader {
+	return &Reader{
+		r:       r,
+		codec:   codec,
+		block:   make([]byte, codec.BlockAlign()),
+		samples: make(audio.Samples[int16], codec.FramesPerBlock()*codec.Channels()),
+	}
+}
+
+func (r *Reader) String() string {
+	return fmt.Sprintf("adpcm decode %s", r.codec)
+}
+
+// ReadSamples decodes whole frames into samples, reading blocks as needed.
+func (r *Reader) ReadSamples(samples audio.Samples[int16]) (int, error) {
+	if len(r.pending) == 0 {
+		n, err := io.ReadFull(r.r, r.block)
+		if err == io.ErrUnexpectedEOF {
+			// The last block may be short.
+			err = nil
+		} else if err != nil {
+			return 0, err
+		}
+		if n, err = r.codec.DecodeBlock(r.samples, r.block[:n]); err != nil {
+			return 0, err
+		}
+		r.pending = r.samples[:n]
+	}
+
+	channels := r.codec.Channels()
+	n := copy(samples[:len(samples)-len(samples)%channels], r.pending)
+	if n == 0 {
+		return 0, io.ErrShortBuffer
+	}
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// Writer encodes samples to blocks written to an io.Writer.
+type Writer struct {
+	w       io.Writer
+	codec   Codec
+	block   []byte
+	samples audio.Samples[int16]
+}
+
+// NewWriter encodes samples to blocks written to w with the codec. Close writes the last block.
+func NewWriter(w io.Writer, codec Codec) *Writer {
+	return &Writer{
+		w:       w,
+		codec:   codec,
+		block:   make([]byte, codec.BlockAlign()),
+		samples: make(audio.Samples[int16], 0, codec.FramesPerBlock()*codec.Channels()),
+	}
+}
+
+func (w *Writer) String() string {
+	return fmt.Sprintf("adpcm encode %s", w.codec)
+}
+
+// WriteSamples buffers samples and writes every complete block.
+func (w *Writer) WriteSamples(samples audio.Samples[int16]) (int, error) {
+	var n int
+	for n < len(samples) {
+		m := copy(w.samples[len(w.samples):cap(w.samples)], samples[n:])
+		w.samples = w.samples[:len(w.samples)+m]
+		n += m
+		if len(w.samples) == cap(w.samples) {
+			if err := w.flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close writes the buffered samples as the last block, padded with silence. It doesn't close the
+// io.Writer.
+func (w *Writer) Close() error {
+	if len(w.samples) == 0 {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *Writer) flush() error {
+	if err := w.codec.EncodeBlock(w.block, w.samples); err != nil {
+		return err
+	}
+	w.samples = w.samples[:0]
+	_, err := w.w.Write(w.block)
+	return err
+}
+
+// clamp a prediction to 16 bits.
+func clamp(v int) int16 {
+	return int16(max(math.MinInt16, min(math.MaxInt16, v)))
+}
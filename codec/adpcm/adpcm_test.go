@@ -0,0 +1,232 @@
+package adpcm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+)
+
+func testLoad(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func testSamples(t *testing.T, name string) audio.Samples[int16] {
+	t.Helper()
+	b := testLoad(t, name)
+	s := make(audio.Samples[int16], len(b)/2)
+	s.Decode(b, binary.LittleEndian)
+	return s
+}
+
+// The IMA fixtures were coded by the IMA/DVI reference coder of Python's audioop module and
+// repacked into WAV blocks, see testdata/gen.py.
+func TestIMAReference(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Channels   int
+		BlockAlign int
+	}{
+		{"ima_mono", 1, 36},
+		{"ima_stereo", 2, 72},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			var (
+				pcm     = testSamples(it, test.Name+".pcm")
+				encoded = testLoad(it, test.Name+".adpcm")
+				decoded = testSamples(it, test.Name+".decoded")
+			)
+
+			c, err := NewIMA(test.Channels, test.BlockAlign)
+			if err != nil {
+				it.Fatal(err)
+			}
+			if v := c.FramesPerBlock(); v != 65 {
+				it.Fatalf("expected 65 frames per block, got %d", v)
+			}
+
+			var buf bytes.Buffer
+			w := NewWriter(&buf, c)
+			if _, err = w.WriteSamples(pcm); err != nil {
+				it.Fatal(err)
+			}
+			if err = w.Close(); err != nil {
+				it.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), encoded) {
+				it.Errorf("encoded blocks don't match the reference:\n%x\n%x", buf.Bytes(), encoded)
+			}
+
+			got := testReadAll(it, NewReader(bytes.NewReader(encoded), c))
+			if !slicesEqual(got, decoded) {
+				it.Errorf("decoded samples don't match the reference:\n%v\n%v", got, decoded)
+			}
+		})
+	}
+}
+
+// The MS fixtures are blocks of random nibbles, decoded by FFmpeg's adpcm_ms decoder, see
+// testdata/gen.py.
+func TestMSReference(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Channels   int
+		BlockAlign int
+	}{
+		{"ms_mono", 1, 32},
+		{"ms_stereo", 2, 64},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			var (
+				encoded = testLoad(it, test.Name+".adpcm")
+				decoded = testSamples(it, test.Name+".decoded")
+			)
+			c, err := NewMS(test.Channels, test.BlockAlign, nil)
+			if err != nil {
+				it.Fatal(err)
+			}
+			got := testReadAll(it, NewReader(bytes.NewReader(encoded), c))
+			if !slicesEqual(got, decoded) {
+				it.Errorf("decoded samples don't match the reference:\n%v\n%v", got, decoded)
+			}
+		})
+	}
+}
+
+func TestMSDecode(t *testing.T) {
+	c, err := NewMS(1, 9, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := []byte{
+		0,     // predictor {256, 0}
+		16, 0, // delta
+		100, 0, // sample 1
+		50, 0, // sample 2
+		0x12, 0xf8,
+	}
+	got := make(audio.Samples[int16], c.FramesPerBlock())
+	n, err := c.DecodeBlock(got, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := audio.Samples[int16]{50, 100, 116, 148, 132, 4}
+	if !slicesEqual(got[:n], want) {
+		t.Errorf("expected %v, got %v", want, got[:n])
+	}
+
+	// Predictor {512, -256} with a negative prediction rounds towards zero.
+	block = []byte{1, 16, 0, 0xfd, 0xff, 0, 0, 0x00, 0x00}
+	n, err = c.DecodeBlock(got, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = audio.Samples[int16]{0, -3, -6, -9, -12, -15}
+	if !slicesEqual(got[:n], want) {
+		t.Errorf("expected %v, got %v", want, got[:n])
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, channels := range []int{1, 2} {
+		ima, err := NewIMA(channels, 256*channels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ms, err := NewMS(channels, 256*channels, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range []Codec{ima, ms} {
+			t.Run(c.String(), func(it *testing.T) {
+				// A few blocks and a partial block of a tone.
+				frames := c.FramesPerBlock()*3 + 100
+				src := make(audio.Samples[int16], frames*channels)
+				for i := range src {
+					frame, ch := i/channels, i%channels
+					src[i] = int16(10000 * math.Sin(2*math.Pi*float64(frame*(ch+1))/100))
+				}
+
+				var buf bytes.Buffer
+				w := NewWriter(&buf, c)
+				if _, err := w.WriteSamples(src); err != nil {
+					it.Fatal(err)
+				}
+				if err := w.Close(); err != nil {
+					it.Fatal(err)
+				}
+				if want := 4 * c.BlockAlign(); buf.Len() != want {
+					it.Fatalf("expected %d bytes, got %d", want, buf.Len())
+				}
+
+				got := testReadAll(it, NewReader(&buf, c))
+				if len(got) != 4*c.FramesPerBlock()*channels {
+					it.Fatalf("expected %d samples, got %d", 4*c.FramesPerBlock()*channels, len(got))
+				}
+				var noise, signal float64
+				for i, v := range src {
+					signal += float64(v) * float64(v)
+					noise += math.Pow(float64(got[i])-float64(v), 2)
+				}
+				if snr := 10 * math.Log10(signal/noise); snr < 20 {
+					it.Errorf("expected a signal to noise ratio of at least 20 dB, got %.1f dB", snr)
+				}
+				// The padding takes a few samples to settle.
+				if v := got[len(got)-1]; v < -300 || v > 300 {
+					it.Errorf("expected padding to be about silent, got %d", v)
+				}
+			})
+		}
+	}
+}
+
+func TestShortBlock(t *testing.T) {
+	c, err := NewIMA(1, 36)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The last block of a stream has a header and one chunk.
+	block := []byte{0x10, 0x00, 0, 0, 0, 0, 0, 0}
+	got := testReadAll(t, NewReader(bytes.NewReader(block), c))
+	if want := (audio.Samples[int16]{16, 16, 16, 16, 16, 16, 16, 16, 16}); !slicesEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if _, err = c.DecodeBlock(make(audio.Samples[int16], 100), block[:6]); err != ErrBlock {
+		t.Errorf("expected ErrBlock, got %v", err)
+	}
+}
+
+func testReadAll(t *testing.T, r audio.Reader[int16]) audio.Samples[int16] {
+	t.Helper()
+	var (
+		all    audio.Samples[int16]
+		buffer = make(audio.Samples[int16], 64)
+	)
+	for {
+		n, err := r.ReadSamples(buffer)
+		all = append(all, buffer[:n]...)
+		if err == io.EOF {
+			return all
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func slicesEqual(a, b audio.Samples[int16]) bool {
+	return len(a) == len(b) && bytes.Equal(a.Bytes(), b.Bytes())
+}
@@ -0,0 +1,199 @@
+package adpcm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/BeatGlow/audio"
+)
+
+var imaSteps = [89]int{
+	7, 8, 9, 10, 11, 12, 13, 14, 16, 17,
+	19, 21, 23, 25, 28, 31, 34, 37, 41, 45,
+	50, 55, 60, 66, 73, 80, 88, 97, 107, 118,
+	130, 143, 157, 173, 190, 209, 230, 253, 279, 307,
+	337, 371, 408, 449, 494, 544, 598, 658, 724, 796,
+	876, 963, 1060, 1166, 1282, 1411, 1552, 1707, 1878, 2066,
+	2272, 2499, 2749, 3024, 3327, 3660, 4026, 4428, 4871, 5358,
+	5894, 6484, 7132, 7845, 8630, 9493, 10442, 11487, 12635, 13899,
+	15289, 16818, 18500, 20350, 22385, 24623, 27086, 29794, 32767,
+}
+
+var imaIndexes = [16]int{-1, -1, -1, -1, 2, 4, 6, 8, -1, -1, -1, -1, 2, 4, 6, 8}
+
+// imaState is the predictor of one channel.
+type imaState struct {
+	predictor int16
+	index     int
+}
+
+// decode a nibble and update the state.
+func (s *imaState) decode(nibble byte) int16 {
+	var (
+		step = imaSteps[s.index]
+		diff = step >> 3
+	)
+	if nibble&4 != 0 {
+		diff += step
+	}
+	if nibble&2 != 0 {
+		diff += step >> 1
+	}
+	if nibble&1 != 0 {
+		diff += step >> 2
+	}
+	if nibble&8 != 0 {
+		s.predictor = clamp(int(s.predictor) - diff)
+	} else {
+		s.predictor = clamp(int(s.predictor) + diff)
+	}
+	s.index = max(0, min(len(imaSteps)-1, s.index+imaIndexes[nibble]))
+	return s.predictor
+}
+
+// encode a sample to a nibble and update the state like the decoder.
+func (s *imaState) encode(v int16) byte {
+	var (
+		step   = imaSteps[s.index]
+		diff   = int(v) - int(s.predictor)
+		nibble byte
+	)
+	if diff < 0 {
+		nibble, diff = 8, -diff
+	}
+	for bit := byte(4); bit > 0; bit >>= 1 {
+		if diff >= step {
+			nibble |= bit
+			diff -= step
+		}
+		step >>= 1
+	}
+	s.decode(nibble)
+	return nibble
+}
+
+// IMA is the IMA ADPCM codec, also known as DVI ADPCM, with the block layout of WAV files.
+//
+// Each block starts with the first sample and the step index of each channel, followed by 8
+// nibbles per channel at a time, low nibble first.
+type IMA struct {
+	channels   int
+	blockAlign int
+
+	// Step indexes carried over between encoded blocks.
+	states []imaState
+}
+
+// NewIMA returns an IMA ADPCM codec for blocks of blockAlign bytes.
+func NewIMA(channels, blockAlign int) (*IMA, error) {
+	if channels < 1 {
+		return nil, ErrChannels
+	}
+	if blockAlign < 8*channels || (blockAlign-4*channels)%(4*channels) != 0 {
+		return nil, ErrBlockAlign
+	}
+	return &IMA{
+		channels:   channels,
+		blockAlign: blockAlign,
+		states:     make([]imaState, channels),
+	}, nil
+}
+
+// Channels is the number of interleaved channels.
+func (c *IMA) Channels() int {
+	return c.channels
+}
+
+// BlockAlign is the size of a block in bytes.
+func (c *IMA) BlockAlign() int {
+	return c.blockAlign
+}
+
+// FramesPerBlock is the number of frames in a block.
+func (c *IMA) FramesPerBlock() int {
+	return imaFrames(c.blockAlign, c.channels)
+}
+
+func imaFrames(size, channels int) int {
+	return (size-4*channels)*2/channels + 1
+}
+
+func (c *IMA) String() string {
+	return fmt.Sprintf("IMA ADPCM, %d channels, %d bytes per block", c.channels, c.blockAlign)
+}
+
+// DecodeBlock decodes a block into dst.
+func (c *IMA) DecodeBlock(dst audio.Samples[int16], block []byte) (int, error) {
+	channels := c.channels
+	if len(block) > c.blockAlign || len(block) < 4*channels || (len(block)-4*channels)%(4*channels) != 0 {
+		return 0, ErrBlock
+	}
+	frames := imaFrames(len(block), channels)
+	if len(dst) < frames*channels {
+		return 0, ErrShort
+	}
+
+	states := make([]imaState, channels)
+	for ch := range states {
+		header := block[ch*4:]
+		states[ch] = imaState{
+			predictor: int16(binary.LittleEndian.Uint16(header)),
+			index:     int(header[2]),
+		}
+		if states[ch].index >= len(imaSteps) {
+			return 0, ErrBlock
+		}
+		dst[ch] = states[ch].predictor
+	}
+
+	// Each channel has 4 bytes of 8 samples in turn.
+	data := block[4*channels:]
+	for chunk := 0; chunk*4*channels < len(data); chunk++ {
+		for ch := range states {
+			for i, b := range data[(chunk*channels+ch)*4 : (chunk*channels+ch+1)*4] {
+				frame := 1 + chunk*8 + i*2
+				dst[frame*channels+ch] = states[ch].decode(b & 0x0f)
+				dst[(frame+1)*channels+ch] = states[ch].decode(b >> 4)
+			}
+		}
+	}
+	return frames * channels, nil
+}
+
+// EncodeBlock encodes src into a block in dst.
+func (c *IMA) EncodeBlock(dst []byte, src audio.Samples[int16]) error {
+	channels := c.channels
+	if len(dst) < c.blockAlign {
+		return ErrShort
+	}
+	frames := c.FramesPerBlock()
+	sample := func(frame, ch int) int16 {
+		if i := frame*channels + ch; i < len(src) {
+			return src[i]
+		}
+		return 0
+	}
+
+	for ch := range c.states {
+		s := &c.states[ch]
+		s.predictor = sample(0, ch)
+		header := dst[ch*4:]
+		binary.LittleEndian.PutUint16(header, uint16(s.predictor))
+		header[2], header[3] = byte(s.index), 0
+	}
+
+	data := dst[4*channels : c.blockAlign]
+	for chunk := 0; chunk*8+1 < frames; chunk++ {
+		for ch := range c.states {
+			s := &c.states[ch]
+			out := data[(chunk*channels+ch)*4 : (chunk*channels+ch+1)*4]
+			for i := range out {
+				frame := 1 + chunk*8 + i*2
+				lo := s.encode(sample(frame, ch))
+				hi := s.encode(sample(frame+1, ch))
+				out[i] = lo | hi<<4
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package adpcm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/BeatGlow/audio"
+)
+
+// MSCoefficients are the standard predictor coefficients of Microsoft ADPCM.
+var MSCoefficients = [][2]int16{
+	{256, 0},
+	{512, -256},
+	{0, 0},
+	{192, 64},
+	{240, 0},
+	{460, -208},
+	{392, -232},
+}
+
+var msAdaption = [16]int{230, 230, 230, 230, 307, 409, 512, 614, 768, 614, 512, 409, 307, 230, 230, 230}
+
+// msMinDelta is the smallest quantization step.
+const msMinDelta = 16
+
+// msState is the predictor of one channel.
+type msState struct {
+	coefficients     [2]int16
+	delta            int
+	sample1, sample2 int16
+}
+
+func (s *msState) predict() int {
+	// Division rounds towards zero like the reference decoder.
+	return (int(s.sample1)*int(s.coefficients[0]) + int(s.sample2)*int(s.coefficients[1])) / 256
+}
+
+// decode a nibble and update the state.
+func (s *msState) decode(nibble byte) int16 {
+	signed := int(nibble)
+	if signed >= 8 {
+		signed -= 16
+	}
+	v := clamp(s.predict() + signed*s.delta)
+	s.sample2, s.sample1 = s.sample1, v
+	s.delta = max(msMinDelta, msAdaption[nibble]*s.delta/256)
+	return v
+}
+
+// encode a sample to a nibble and update the state like the decoder.
+func (s *msState) encode(v int16) byte {
+	diff := float64(int(v)-s.predict()) / float64(s.delta)
+	nibble := int(max(-8, min(7, math.Round(diff))))
+	s.decode(byte(nibble & 0x0f))
+	return byte(nibble & 0x0f)
+}
+
+// MS is the Microsoft ADPCM codec, with the block layout of WAV files.
+//
+// Each block starts with the predictor, quantization step and first two samples of each channel,
+// followed by interleaved nibbles, high nibble first.
+type MS struct {
+	channels     int
+	blockAlign   int
+	coefficients [][2]int16
+}
+
+// NewMS returns a Microsoft ADPCM codec for blocks of blockAlign bytes. The coefficients are the
+// predictors that blocks can select, nil selects MSCoefficients.
+func NewMS(channels, blockAlign int, coefficients [][2]int16) (*MS, error) {
+	if channels < 1 {
+		return nil, ErrChannels
+	}
+	if blockAlign < 7*channels {
+		return nil, ErrBlockAlign
+	}
+	if coefficients == nil {
+		coefficients = MSCoefficients
+	} else if len(coefficients) == 0 || len(coefficients) > 256 {
+		return nil, fmt.Errorf("adpcm: need 1 to 256 coefficient pairs, got %d", len(coefficients))
+	}
+	return &MS{
+		channels:     channels,
+		blockAlign:   blockAlign,
+		coefficients: coefficients,
+	}, nil
+}
+
+// Channels is the number of interleaved channels.
+func (c *MS) Channels() int {
+	return c.channels
+}
+
+// BlockAlign is the size of a block in bytes.
+func (c *MS) BlockAlign() int {
+	return c.blockAlign
+}
+
+// FramesPerBlock is the number of frames in a block.
+func (c *MS) FramesPerBlock() int {
+	return msFrames(c.blockAlign, c.channels)
+}
+
+func msFrames(size, channels int) int {
+	return (size-7*channels)*2/channels + 2
+}
+
+// Coefficients are the predictors that blocks can select.
+func (c *MS) Coefficients() [][2]int16 {
+	return c.coefficients
+}
+
+func (c *MS) String() string {
+	return fmt.Sprintf("MS ADPCM, %d channels, %d bytes per block", c.channels, c.blockAlign)
+}
+
+// DecodeBlock decodes a block into dst.
+func (c *MS) DecodeBlock(dst audio.Samples[int16], block []byte) (int, error) {
+	channels := c.channels
+	if len(block) > c.blockAlign || len(block) < 7*channels {
+		return 0, ErrBlock
+	}
+	frames := msFrames(len(block), channels)
+	if len(dst) < frames*channels {
+		return 0, ErrShort
+	}
+
+	states := make([]msState, channels)
+	for ch := range states {
+		predictor := int(block[ch])
+		if predictor >= len(c.coefficients) {
+			return 0, ErrBlock
+		}
+		states[ch] = msState{
+			coefficients: c.coefficients[predictor],
+			delta:        int(int16(binary.LittleEndian.Uint16(block[channels+ch*2:]))),
+			sample1:      int16(binary.LittleEndian.Uint16(block[3*channels+ch*2:])),
+			sample2:      int16(binary.LittleEndian.Uint16(block[5*channels+ch*2:])),
+		}
+		dst[ch] = states[ch].sample2
+		dst[channels+ch] = states[ch].sample1
+	}
+
+	// Nibbles alternate between channels, so each byte holds two samples.
+	i := 2 * channels
+	for _, b := range block[7*channels : 7*channels+(frames-2)*channels/2] {
+		dst[i] = states[i%channels].decode(b >> 4)
+		dst[i+1] = states[(i+1)%channels].decode(b & 0x0f)
+		i += 2
+	}
+	return frames * channels, nil
+}
+
+// EncodeBlock encodes src into a block in dst, selecting the predictor with the smallest error for
+// each channel.
+func (c *MS) EncodeBlock(dst []byte, src audio.Samples[int16]) error {
+	channels := c.channels
+	if len(dst) < c.blockAlign {
+		return ErrShort
+	}
+	frames := c.FramesPerBlock()
+	sample := func(frame, ch int) int16 {
+		if i := frame*channels + ch; i < len(src) {
+			return src[i]
+		}
+		return 0
+	}
+
+	states := make([]msState, channels)
+	for ch := range states {
+		best, bestError := 0, math.Inf(1)
+		for predictor, coefficients := range c.coefficients {
+			s := c.start(coefficients, sample(0, ch), sample(1, ch))
+			var squared float64
+			for frame := 2; frame < frames; frame++ {
+				v := sample(frame, ch)
+				s.encode(v)
+				squared += math.Pow(float64(v)-float64(s.sample1), 2)
+			}
+			if squared < bestError {
+				best, bestError = predictor, squared
+			}
+		}
+
+		states[ch] = c.start(c.coefficients[best], sample(0, ch), sample(1, ch))
+		dst[ch] = byte(best)
+		binary.LittleEndian.PutUint16(dst[channels+ch*2:], uint16(states[ch].delta))
+		binary.LittleEndian.PutUint16(dst[3*channels+ch*2:], uint16(states[ch].sample1))
+		binary.LittleEndian.PutUint16(dst[5*channels+ch*2:], uint16(states[ch].sample2))
+	}
+
+	i := 2 * channels
+	for j := range dst[7*channels : c.blockAlign] {
+		var b byte
+		if i < frames*channels {
+			b = states[i%channels].encode(sample(i/channels, i%channels)) << 4
+			b |= states[(i+1)%channels].encode(sample((i+1)/channels, (i+1)%channels))
+		}
+		dst[7*channels+j] = b
+		i += 2
+	}
+	return nil
+}
+
+// start returns the state after the first two samples, with an initial step that matches the
+// difference between them.
+func (c *MS) start(coefficients [2]int16, first, second int16) msState {
+	return msState{
+		coefficients: coefficients,
+		delta:        max(msMinDelta, abs(int(second)-int(first))/4),
+		sample1:      second,
+		sample2:      first,
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,91 @@
+# Generates the ADPCM fixtures, run with Python 3.12 or earlier and FFmpeg: python3 gen.py
+#
+# IMA: Python's audioop implements the IMA/DVI reference coder on headerless nibbles. Each block
+# channel is coded by audioop from the block header (first sample and step index), and the
+# nibbles are repacked into the WAV block layout: 4 bytes per channel, low nibble first.
+#
+# MS: blocks of random nibbles in a WAVE file, decoded by FFmpeg's adpcm_ms decoder:
+#
+#   ffmpeg -v error -y -i ms_mono.wav -f s16le -c:a pcm_s16le ms_mono.decoded
+import audioop
+import math
+import os
+import random
+import struct
+import subprocess
+import tempfile
+
+random.seed(1)
+
+
+def ima(name, channels, block_align, blocks):
+    frames = (block_align - 4 * channels) * 2 // channels + 1
+    pcm = []
+    for i in range(frames * blocks):
+        for ch in range(channels):
+            v = 12000 * math.sin(2 * math.pi * i * (ch + 1) / 37) + random.randint(-2000, 2000)
+            pcm.append(int(v))
+
+    index = [0] * channels
+    encoded, decoded = b"", []
+    for b in range(blocks):
+        start = b * frames
+        header, nibbles, out = b"", [], []
+        for ch in range(channels):
+            s = [pcm[(start + f) * channels + ch] for f in range(frames)]
+            header += struct.pack("<hBB", s[0], index[ch], 0)
+            data, state = audioop.lin2adpcm(struct.pack("<%dh" % (frames - 1), *s[1:]), 2, (s[0], index[ch]))
+            dec, _ = audioop.adpcm2lin(data, 2, (s[0], index[ch]))
+            index[ch] = state[1]
+            # audioop stores the first nibble in the high bits.
+            nibbles.append([n for byte in data for n in (byte >> 4, byte & 0x0f)])
+            out.append([s[0]] + list(struct.unpack("<%dh" % (frames - 1), dec)))
+        body = b""
+        for k in range(0, frames - 1, 8):
+            for ch in range(channels):
+                n = nibbles[ch][k:k + 8]
+                body += bytes(n[j] | n[j + 1] << 4 for j in range(0, 8, 2))
+        encoded += header + body
+        decoded += [out[ch][f] for f in range(frames) for ch in range(channels)]
+
+    write(name + ".pcm", pcm)
+    open(name + ".adpcm", "wb").write(encoded)
+    write(name + ".decoded", decoded)
+
+
+COEFFICIENTS = [(256, 0), (512, -256), (0, 0), (192, 64), (240, 0), (460, -208), (392, -232)]
+
+
+def ms(name, channels, block_align, blocks):
+    encoded = b""
+    for _ in range(blocks):
+        block = bytes(random.randrange(7) for _ in range(channels))
+        block += struct.pack("<%dh" % channels, *(random.randint(16, 1500) for _ in range(channels)))
+        block += struct.pack("<%dh" % (2 * channels), *(random.randint(-8000, 8000) for _ in range(2 * channels)))
+        block += bytes(random.randrange(256) for _ in range(block_align - 7 * channels))
+        encoded += block
+    open(name + ".adpcm", "wb").write(encoded)
+
+    # WAVE_FORMAT_ADPCM with the standard coefficients.
+    rate, frames = 8000, (block_align - 7 * channels) * 2 // channels + 2
+    fmt = struct.pack("<HHIIHHHHH", 2, channels, rate, rate * block_align // frames, block_align, 4, 32, frames, 7)
+    fmt += b"".join(struct.pack("<hh", *c) for c in COEFFICIENTS)
+    body = b"WAVE" + chunk(b"fmt ", fmt) + chunk(b"fact", struct.pack("<I", frames * blocks)) + chunk(b"data", encoded)
+    with tempfile.TemporaryDirectory() as tmp:
+        wav = os.path.join(tmp, name + ".wav")
+        open(wav, "wb").write(b"RIFF" + struct.pack("<I", len(body)) + body)
+        subprocess.run(["ffmpeg", "-v", "error", "-y", "-i", wav, "-f", "s16le", "-c:a", "pcm_s16le", name + ".decoded"], check=True)
+
+
+def chunk(id, body):
+    return id + struct.pack("<I", len(body)) + body + b"\0" * (len(body) & 1)
+
+
+def write(name, samples):
+    open(name, "wb").write(struct.pack("<%dh" % len(samples), *samples))
+
+
+ima("ima_mono", 1, 36, 3)
+ima("ima_stereo", 2, 72, 3)
+ms("ms_mono", 1, 32, 2)
+ms("ms_stereo", 2, 64, 2)
@@ -0,0 +1,253 @@
+// Package g711 implements the A-law and µ-law companding of ITU-T G.711.
+//
+// Companded samples are 8 bits, which decode to 16-bit linear samples. A-law has 13 bits of
+// precision and µ-law has 14 bits, the remaining bits are zero.
+package g711
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/scratch"
+)
+
+var (
+	alawDecode [256]int16
+	ulawDecode [256]int16
+
+	// The encode tables are indexed by the significant bits of the linear sample.
+	alawEncode [1 << 13]byte
+	ulawEncode [1 << 14]byte
+)
+
+func init() {
+	for i := range 256 {
+		alawDecode[i] = alawToLinear(byte(i))
+		ulawDecode[i] = ulawToLinear(byte(i))
+	}
+	for i := range alawEncode {
+		alawEncode[i] = linearToALaw(int16(i<<3) ^ -0x8000)
+	}
+	for i := range ulawEncode {
+		ulawEncode[i] = linearToULaw(int16(i<<2) ^ -0x8000)
+	}
+}
+
+// DecodeALaw returns the linear value of an A-law sample.
+func DecodeALaw(v byte) int16 {
+	return alawDecode[v]
+}
+
+// EncodeALaw returns the A-law sample of a linear value.
+func EncodeALaw(v int16) byte {
+	return alawEncode[uint16(v)>>3^0x1000]
+}
+
+// DecodeMuLaw returns the linear value of a µ-law sample.
+func DecodeMuLaw(v byte) int16 {
+	return ulawDecode[v]
+}
+
+// EncodeMuLaw returns the µ-law sample of a linear value.
+func EncodeMuLaw(v int16) byte {
+	return ulawEncode[uint16(v)>>2^0x2000]
+}
+
+// Segment end points of the 13-bit A-law and 14-bit µ-law ranges.
+var (
+	alawSegments = [8]int16{0x1f, 0x3f, 0x7f, 0xff, 0x1ff, 0x3ff, 0x7ff, 0xfff}
+	ulawSegments = [8]int16{0x3f, 0x7f, 0xff, 0x1ff, 0x3ff, 0x7ff, 0xfff, 0x1fff}
+)
+
+const (
+	ulawBias = 0x84
+	ulawClip = 8159
+)
+
+// segment returns the segment of v, which is 8 if v exceeds all segments.
+func segment(v int16, segments *[8]int16) int {
+	for i, end := range segments {
+		if v <= end {
+			return i
+		}
+	}
+	return len(segments)
+}
+
+// linearToALaw is the reference encoder for the tables.
+func linearToALaw(v int16) byte {
+	var mask byte = 0xd5
+	v >>= 3
+	if v < 0 {
+		mask = 0x55
+		v = -v - 1
+	}
+	seg := segment(v, &alawSegments)
+	if seg >= 8 {
+		return 0x7f ^ mask
+	}
+	a := byte(seg << 4)
+	if seg < 2 {
+		a |= byte(v>>1) & 0x0f
+	} else {
+		a |= byte(v>>seg) & 0x0f
+	}
+	return a ^ mask
+}
+
+// alawToLinear is the reference decoder for the tables.
+func alawToLinear(a byte) int16 {
+	a ^= 0x55
+	var (
+		t   = int16(a&0x0f) << 4
+		seg = int(a&0x70) >> 4
+	)
+	switch seg {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t += 0x108
+		t <<= seg - 1
+	}
+	if a&0x80 != 0 {
+		return t
+	}
+	return -t
+}
+
+// linearToULaw is the reference encoder for the tables.
+func linearToULaw(v int16) byte {
+	var mask byte = 0xff
+	v >>= 2
+	if v < 0 {
+		// One's complement like the ITU-T G.191 reference, so -1 encodes as negative zero.
+		mask = 0x7f
+		v = ^v
+	}
+	v = min(v, ulawClip) + ulawBias>>2
+	seg := segment(v, &ulawSegments)
+	if seg >= 8 {
+		return 0x7f ^ mask
+	}
+	return (byte(seg<<4) | byte(v>>(seg+1))&0x0f) ^ mask
+}
+
+// ulawToLinear is the reference decoder for the tables.
+func ulawToLinear(u byte) int16 {
+	u = ^u
+	t := (int16(u&0x0f)<<3 + ulawBias) << ((u & 0x70) >> 4)
+	if u&0x80 != 0 {
+		return ulawBias - t
+	}
+	return t - ulawBias
+}
+
+// codec returns the decoder and encoder for the encoding of a format.
+func codec(format audio.Format) (func(byte) int16, func(int16) byte, error) {
+	if err := format.Validate(); err != nil {
+		return nil, nil, err
+	}
+	switch format.Encoding {
+	case audio.ALaw:
+		return DecodeALaw, EncodeALaw, nil
+	case audio.MuLaw:
+		return DecodeMuLaw, EncodeMuLaw, nil
+	default:
+		return nil, nil, fmt.Errorf("g711: can't decode %s samples", format.Encoding)
+	}
+}
+
+type reader[T audio.Sample] struct {
+	r      io.Reader
+	format audio.Format
+	decode func(byte) int16
+	buf    []byte
+	linear audio.Samples[int16]
+}
+
+// NewReader returns a Reader that decodes A-law or µ-law samples in the given format from r.
+func NewReader[T audio.Sample](r io.Reader, format audio.Format) (audio.FormatReader[T], error) {
+	decode, _, err := codec(format)
+	if err != nil {
+		return nil, err
+	}
+	return &reader[T]{
+		r:      r,
+		format: format,
+		decode: decode,
+	}, nil
+}
+
+// Format of the companded samples.
+func (r *reader[T]) Format() audio.Format {
+	return r.format
+}
+
+func (r *reader[T]) String() string {
+	return fmt.Sprintf("g711 decode %s", r.format.Encoding)
+}
+
+func (r *reader[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	if len(samples) == 0 {
+		return 0, io.ErrShortBuffer
+	}
+	r.buf = scratch.Grow(r.buf, len(samples))
+	n, err := io.ReadFull(r.r, r.buf)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	r.linear = scratch.Grow(r.linear, n)
+	for i, v := range r.buf[:n] {
+		r.linear[i] = r.decode(v)
+	}
+	audio.Convert(samples, r.linear)
+	if n > 0 && err == io.EOF {
+		// The samples read before the end are returned first.
+		return n, nil
+	}
+	return n, err
+}
+
+type writer[T audio.Sample] struct {
+	w      io.Writer
+	format audio.Format
+	encode func(int16) byte
+	buf    []byte
+	linear audio.Samples[int16]
+}
+
+// NewWriter returns a Writer that encodes samples as A-law or µ-law samples in the given format
+// to w. Samples are converted to 16 bits first, louder samples saturate.
+func NewWriter[T audio.Sample](w io.Writer, format audio.Format) (audio.FormatWriter[T], error) {
+	_, encode, err := codec(format)
+	if err != nil {
+		return nil, err
+	}
+	return &writer[T]{
+		w:      w,
+		format: format,
+		encode: encode,
+	}, nil
+}
+
+// Format of the companded samples.
+func (w *writer[T]) Format() audio.Format {
+	return w.format
+}
+
+func (w *writer[T]) String() string {
+	return fmt.Sprintf("g711 encode %s", w.format.Encoding)
+}
+
+func (w *writer[T]) WriteSamples(samples audio.Samples[T]) (int, error) {
+	w.linear = scratch.Grow(w.linear, len(samples))
+	audio.Convert(w.linear, samples)
+	w.buf = scratch.Grow(w.buf, len(samples))
+	for i, v := range w.linear {
+		w.buf[i] = w.encode(v)
+	}
+	return w.w.Write(w.buf)
+}
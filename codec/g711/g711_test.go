@@ -0,0 +1,139 @@
+package g711
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/BeatGlow/audio"
+)
+
+func TestDecode(t *testing.T) {
+	// Reference values of ITU-T G.711 tables 1a and 2a, scaled to 16 bits.
+	testCases := []struct {
+		Name   string
+		Decode func(byte) int16
+		Code   byte
+		Want   int16
+	}{
+		{"alaw min positive", DecodeALaw, 0xd5, 8},
+		{"alaw min negative", DecodeALaw, 0x55, -8},
+		{"alaw segment 1", DecodeALaw, 0xc5, 264},
+		{"alaw max positive", DecodeALaw, 0xaa, 32256},
+		{"alaw max negative", DecodeALaw, 0x2a, -32256},
+		{"ulaw zero", DecodeMuLaw, 0xff, 0},
+		{"ulaw negative zero", DecodeMuLaw, 0x7f, 0},
+		{"ulaw one step", DecodeMuLaw, 0xfe, 8},
+		{"ulaw segment 1", DecodeMuLaw, 0xef, 132},
+		{"ulaw max positive", DecodeMuLaw, 0x80, 32124},
+		{"ulaw max negative", DecodeMuLaw, 0x00, -32124},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			if v := test.Decode(test.Code); v != test.Want {
+				it.Errorf("expected %#02x to decode to %d, got %d", test.Code, test.Want, v)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Encode func(int16) byte
+		Value  int16
+		Want   byte
+	}{
+		{"alaw zero", EncodeALaw, 0, 0xd5},
+		{"alaw negative", EncodeALaw, -1, 0x55},
+		{"alaw max", EncodeALaw, 32767, 0xaa},
+		{"alaw min", EncodeALaw, -32768, 0x2a},
+		{"alaw 1000", EncodeALaw, 1000, 0xfa},
+		{"ulaw zero", EncodeMuLaw, 0, 0xff},
+		{"ulaw negative", EncodeMuLaw, -1, 0x7f},
+		{"ulaw max", EncodeMuLaw, 32767, 0x80},
+		{"ulaw min", EncodeMuLaw, -32768, 0x00},
+		{"ulaw 1000", EncodeMuLaw, 1000, 0xce},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			if v := test.Encode(test.Value); v != test.Want {
+				it.Errorf("expected %d to encode to %#02x, got %#02x", test.Value, test.Want, v)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for i := range 256 {
+		code := byte(i)
+		if v := EncodeALaw(DecodeALaw(code)); v != code {
+			t.Errorf("expected A-law %#02x to round trip, got %#02x", code, v)
+		}
+		// Negative zero encodes as positive zero.
+		if v := EncodeMuLaw(DecodeMuLaw(code)); v != code && code != 0x7f {
+			t.Errorf("expected µ-law %#02x to round trip, got %#02x", code, v)
+		}
+	}
+
+	// The tables match the reference implementation for every value.
+	for v := -32768; v < 32768; v++ {
+		if a, b := EncodeALaw(int16(v)), linearToALaw(int16(v)); a != b {
+			t.Fatalf("expected A-law of %d to be %#02x, got %#02x", v, b, a)
+		}
+		if a, b := EncodeMuLaw(int16(v)), linearToULaw(int16(v)); a != b {
+			t.Fatalf("expected µ-law of %d to be %#02x, got %#02x", v, b, a)
+		}
+	}
+}
+
+func TestReaderWriter(t *testing.T) {
+	for _, encoding := range []audio.Encoding{audio.ALaw, audio.MuLaw} {
+		t.Run(encoding.String(), func(it *testing.T) {
+			format := audio.Format{SampleRate: 8000, Channels: 1, Encoding: encoding, Bits: 8}
+			samples := audio.Samples[float32]{0, 0.5, -0.5, 1, -1}
+
+			var buf bytes.Buffer
+			w, err := NewWriter[float32](&buf, format)
+			if err != nil {
+				it.Fatal(err)
+			}
+			if n, err := w.WriteSamples(samples); err != nil || n != len(samples) {
+				it.Fatalf("expected %d samples written, got %d, %v", len(samples), n, err)
+			}
+
+			r, err := NewReader[float32](&buf, format)
+			if err != nil {
+				it.Fatal(err)
+			}
+			got := make(audio.Samples[float32], 8)
+			n, err := r.ReadSamples(got)
+			if err != nil || n != len(samples) {
+				it.Fatalf("expected %d samples read, got %d, %v", len(samples), n, err)
+			}
+			for i, v := range samples {
+				if d := got[i] - v; d < -0.02 || d > 0.02 {
+					it.Errorf("expected sample %d to be about %g, got %g", i, v, got[i])
+				}
+			}
+			if _, err = r.ReadSamples(got); err != io.EOF {
+				it.Errorf("expected io.EOF, got %v", err)
+			}
+		})
+	}
+
+	// Errors after some samples are returned with the samples.
+	format := audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.ALaw, Bits: 8}
+	r, err := NewReader[int16](io.MultiReader(bytes.NewReader([]byte{0xd5, 0x55}), iotest.ErrReader(iotest.ErrTimeout)), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := r.ReadSamples(make(audio.Samples[int16], 4)); n != 2 || err != iotest.ErrTimeout {
+		t.Errorf("expected 2 samples and %v, got %d, %v", iotest.ErrTimeout, n, err)
+	}
+
+	if _, err := NewReader[int16](nil, audio.FormatOf[uint8](8000, 1, nil)); err == nil {
+		t.Error("expected error for linear format")
+	}
+}
@@ -0,0 +1,90 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// ContextReader can read samples until a context is done.
+type ContextReader[T Sample] interface {
+	ReadSamplesContext(context.Context, Samples[T]) (int, error)
+}
+
+// ContextWriter can write samples until a context is done.
+type ContextWriter[T Sample] interface {
+	WriteSamplesContext(context.Context, Samples[T]) (int, error)
+}
+
+// ReadSamplesContext reads samples from r, and stops when ctx is done if r is a ContextReader.
+// Other readers can't be interrupted, ctx is only checked before reading.
+func ReadSamplesContext[T Sample](ctx context.Context, r Reader[T], samples Samples[T]) (int, error) {
+	if cr, ok := r.(ContextReader[T]); ok {
+		return cr.ReadSamplesContext(ctx, samples)
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.ReadSamples(samples)
+}
+
+// WriteSamplesContext writes samples to w, and stops when ctx is done if w is a ContextWriter.
+// Other writers can't be interrupted, ctx is only checked before writing.
+func WriteSamplesContext[T Sample](ctx context.Context, w Writer[T], samples Samples[T]) (int, error) {
+	if cw, ok := w.(ContextWriter[T]); ok {
+		return cw.WriteSamplesContext(ctx, samples)
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.WriteSamples(samples)
+}
+
+// readDeadliner is implemented by files, pipes and network connections.
+type readDeadliner interface {
+	SetReadDeadline(time.Time) error
+}
+
+// writeDeadliner is implemented by files, pipes and network connections.
+type writeDeadliner interface {
+	SetWriteDeadline(time.Time) error
+}
+
+// aLongTimeAgo is a deadline in the past, which interrupts blocked reads and writes.
+var aLongTimeAgo = time.Unix(1, 0)
+
+// withContext calls fn, which is interrupted by setting a deadline in the past once ctx is done.
+// The deadline is reset to deadline afterwards. If set is nil or doesn't support deadlines, fn
+// is called without interruption.
+func withContext(ctx context.Context, set func(time.Time) error, deadline time.Time, fn func() (int, error)) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if set == nil || ctx.Done() == nil {
+		return fn()
+	}
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		if err := set(d); err != nil {
+			return fn()
+		}
+	} else if err := set(deadline); err != nil {
+		return fn()
+	}
+
+	done := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		_ = set(aLongTimeAgo)
+		close(done)
+	})
+	n, err := fn()
+	if !stop() {
+		<-done
+	}
+	_ = set(deadline)
+
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	return n, err
+}
@@ -0,0 +1,135 @@
+package audio_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/BeatGlow/audio"
+)
+
+func testPipe(t *testing.T) (*os.File, *os.File) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = r.Close()
+		_ = w.Close()
+	})
+	return r, w
+}
+
+func TestReadSamplesContext(t *testing.T) {
+	format := audio.FormatOf[int16](44100, 2, binary.LittleEndian)
+
+	t.Run("cancel", func(t *testing.T) {
+		pr, pw := testPipe(t)
+		r, err := audio.NewReader[int16](pr, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		if _, err = audio.ReadSamplesContext(ctx, r, make(audio.Samples[int16], 4)); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+
+		// The deadline is reset after the cancelled read.
+		if _, err = pw.Write([]byte{1, 0, 2, 0}); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := r.ReadSamples(make(audio.Samples[int16], 2)); err != nil || n != 2 {
+			t.Fatalf("expected 2 samples, got %d, %v", n, err)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		pr, _ := testPipe(t)
+		r, err := audio.NewReader[int16](pr, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err = audio.ReadSamplesContext(ctx, r, make(audio.Samples[int16], 4)); !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+	})
+
+	t.Run("read deadline", func(t *testing.T) {
+		pr, _ := testPipe(t)
+		r, err := audio.NewReader[int16](pr, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = r.(interface{ SetReadDeadline(time.Time) error }).SetReadDeadline(time.Now().Add(10 * time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = r.ReadSamples(make(audio.Samples[int16], 4)); !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("expected os.ErrDeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("data", func(t *testing.T) {
+		pr, pw := testPipe(t)
+		r, err := audio.NewReader[int16](pr, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = pw.Write([]byte{1, 0, 2, 0}); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		samples := make(audio.Samples[int16], 2)
+		n, err := audio.ReadSamplesContext(ctx, r, samples)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 || samples[0] != 1 || samples[1] != 2 {
+			t.Fatalf("expected [1 2], got %v", samples[:n])
+		}
+	})
+
+	t.Run("no deadline", func(t *testing.T) {
+		r, err := audio.NewReader[int16](testZero{}, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = r.(interface{ SetReadDeadline(time.Time) error }).SetReadDeadline(time.Now()); !errors.Is(err, os.ErrNoDeadline) {
+			t.Fatalf("expected os.ErrNoDeadline, got %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err = audio.ReadSamplesContext(ctx, r, make(audio.Samples[int16], 4)); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestWriteSamplesContext(t *testing.T) {
+	format := audio.FormatOf[int16](44100, 1, binary.LittleEndian)
+
+	_, pw := testPipe(t)
+	w, err := audio.NewWriter[int16](pw, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fill the pipe buffer, so the write blocks.
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err = audio.WriteSamplesContext(ctx, w, make(audio.Samples[int16], 1<<20)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
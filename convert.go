@@ -3,6 +3,7 @@ package audio
 import (
 	"fmt"
 	"math"
+	"math/rand/v2"
 )
 
 const (
@@ -13,77 +14,256 @@ const (
 	int64ToFloat = math.MaxInt64 + 1
 )
 
-// ToInt16 converts samples in s to []int16 in dst.
+// Rounding selects how values are rounded when a conversion loses precision.
+type Rounding int
+
+const (
+	// RoundNearest rounds to the nearest value, with halfway values rounded away from zero.
+	RoundNearest Rounding = iota
+
+	// RoundNearestEven rounds to the nearest value, with halfway values rounded to the nearest
+	// even value.
+	RoundNearestEven
+
+	// RoundFloor rounds towards negative infinity, which is the same as dropping the least
+	// significant bits of an integer.
+	RoundFloor
+
+	// RoundTruncate rounds towards zero.
+	RoundTruncate
+)
+
+func (r Rounding) String() string {
+	switch r {
+	case RoundNearest:
+		return "nearest"
+	case RoundNearestEven:
+		return "nearest even"
+	case RoundFloor:
+		return "floor"
+	case RoundTruncate:
+		return "truncate"
+	default:
+		return fmt.Sprintf("unknown rounding %d", r)
+	}
+}
+
+// Converter converts samples of type S to samples of type D.
+//
+// The full scale range of S is mapped to the full scale range of D: integer samples are scaled
+// by their number of bits, unsigned samples are biased around the midpoint of their range and
+// floating point samples have a range of [-1..1]. Values that don't fit in D are saturated.
+//
+// The zero value is a Converter that rounds to the nearest value without dither.
+type Converter[S, D Sample] struct {
+	// Rounding used when the conversion loses precision.
+	Rounding Rounding
+
+	// Dither added when converting to an integer type with less precision.
+	Dither Dither
+
+	// Shaping are the coefficients of the noise shaping filter applied to the quantization
+	// error, such as LipshitzShaping or FWeightedShaping. Noise shaping is only applied when
+	// dithering.
+	Shaping []float64
+
+	// Rand is the random number generator used for dither, if nil the top-level functions of
+	// math/rand/v2 are used.
+	Rand *rand.Rand
+
+	// Channels is the number of interleaved channels in the samples passed to Convert, so noise
+	// shaping is done per channel. Zero is the same as one channel.
+	Channels int
+
+	// errors is the quantization error history per channel, most recent first.
+	errors [][]float64
+}
+
+// Convert converts samples in src to dst.
 //
 // The provided buffer dst should be nil, or adequately sized to fit all samples.
-func (s Samples[T]) ToInt16(dst []int16) []int16 {
+func (c *Converter[S, D]) Convert(dst Samples[D], src Samples[S]) Samples[D] {
 	if dst == nil {
-		dst = make([]int16, len(s))
+		dst = make(Samples[D], len(src))
 	}
 
-	var value T
-	switch any(value).(type) {
-	case []int:
-		var shift = 48 // for 64-bit
-		if intSize == 32 {
-			shift = 16 // for 32-bit
-		}
-		for i, v := range s {
-			dst[i] = int16(int(v) >> shift)
-		}
-	case []int8:
-		for i, v := range s {
-			dst[i] = int16(v)<<8 | int16(v)
-		}
-	case []int16:
-		for i, v := range s {
-			dst[i] = int16(v)
-		}
-	case []int32:
-		for i, v := range s {
-			dst[i] = int16(int32(v) >> 16)
-		}
-	case []int64:
-		for i, v := range s {
-			dst[i] = int16(int64(v) >> 48)
-		}
-	case []uint:
-		var shift = 48 // for 64-bit
-		if intSize == 32 {
-			shift = 16 // for 32-bit
-		}
-		for i, v := range s {
-			dst[i] = int16(uint(v) >> shift)
-		}
-	case []uint8:
-		for i, v := range s {
-			dst[i] = int16(uint16(v)<<8 | uint16(v))
-		}
-	case []uint16:
-		for i, v := range s {
-			dst[i] = int16(v)
-		}
-	case []uint32:
-		for i, v := range s {
-			dst[i] = int16(uint32(v) >> 16)
-		}
-	case []uint64:
-		for i, v := range s {
-			dst[i] = int16(uint64(v) >> 48)
+	channels := c.Channels
+	if channels < 1 {
+		channels = 1
+	}
+	c.convert(dst, src, 0, channels)
+
+	return dst
+}
+
+// convert src to dst, the first sample belongs to channel and src has channels interleaved channels.
+func (c *Converter[S, D]) convert(dst Samples[D], src Samples[S], channel, channels int) {
+	var (
+		srcBits, srcEncoding = Samples[S]{}.BitsPerSample(), encodingOf[S]()
+		dstBits, dstEncoding = Samples[D]{}.BitsPerSample(), encodingOf[D]()
+	)
+	switch {
+	case c.Dither != NoDither && dstEncoding != Float && (srcEncoding == Float || srcBits > dstBits):
+		c.convertDither(dst, src, channel, channels)
+	case srcEncoding == Float && dstEncoding == Float:
+		for i, v := range src {
+			dst[i] = D(v)
+		}
+	case srcEncoding == Float:
+		for i, v := range src {
+			dst[i] = fromFloat[D](float64(v), dstBits, dstEncoding, c.Rounding)
+		}
+	case dstEncoding == Float:
+		for i, v := range src {
+			dst[i] = D(float64(toFixed(v, srcBits, srcEncoding)) / fixedToFloat)
+		}
+	case srcBits == dstBits && srcEncoding == dstEncoding:
+		for i, v := range src {
+			dst[i] = D(v)
 		}
-	case []float32:
-		for i, v := range s {
-			dst[i] = int16(float32(v)*int16ToFloat + .5)
+	default:
+		for i, v := range src {
+			dst[i] = fromFixed[D](toFixed(v, srcBits, srcEncoding), dstBits, dstEncoding, c.Rounding)
 		}
-	case []float64:
-		for i, v := range s {
-			dst[i] = int16(float64(v)*int16ToFloat + .5)
+	}
+}
+
+// ConvertBuffer converts all channels in src to dst.
+//
+// The provided buffer dst should be nil, or adequately sized to fit all samples in all channels.
+func (c *Converter[S, D]) ConvertBuffer(dst Buffer[D], src Buffer[S]) Buffer[D] {
+	if dst == nil {
+		dst = make(Buffer[D], len(src))
+	}
+
+	for i := range src {
+		if dst[i] == nil {
+			dst[i] = make(Samples[D], len(src[i]))
 		}
+		c.convert(dst[i], src[i], i, 1)
 	}
 
 	return dst
 }
 
+// Convert converts samples in src to dst, rounding to the nearest value.
+//
+// The provided buffer dst should be nil, or adequately sized to fit all samples.
+func Convert[S, D Sample](dst Samples[D], src Samples[S]) Samples[D] {
+	var c Converter[S, D]
+	return c.Convert(dst, src)
+}
+
+// ConvertBuffer converts all channels in src to dst, rounding to the nearest value.
+//
+// The provided buffer dst should be nil, or adequately sized to fit all samples in all channels.
+func ConvertBuffer[S, D Sample](dst Buffer[D], src Buffer[S]) Buffer[D] {
+	var c Converter[S, D]
+	return c.ConvertBuffer(dst, src)
+}
+
+// fixedToFloat scales a left aligned 64-bit value to [-1..1].
+const fixedToFloat = 1 << 63
+
+// toFixed converts an integer sample with the given number of bits to a signed value, aligned
+// to the most significant bit of an int64.
+func toFixed[T Sample](v T, bits int, encoding Encoding) int64 {
+	if encoding == Unsigned {
+		return int64(uint64(v)<<(64-bits) ^ (1 << 63))
+	}
+	return int64(v) << (64 - bits)
+}
+
+// fromFixed converts a signed value aligned to the most significant bit of an int64 to an
+// integer sample with the given number of bits.
+func fromFixed[T Sample](v int64, bits int, encoding Encoding, rounding Rounding) T {
+	if shift := uint(64 - bits); shift > 0 {
+		v = roundShift(v, shift, rounding)
+	}
+	return fromInt[T](v, bits, encoding)
+}
+
+// roundShift shifts v right by shift bits, rounding the result.
+func roundShift(v int64, shift uint, rounding Rounding) int64 {
+	var (
+		half = int64(1) << (shift - 1)
+		mask = int64(1)<<shift - 1
+		max  = int64(math.MaxInt64) >> shift
+	)
+	switch rounding {
+	case RoundNearest:
+		if v < 0 {
+			return (v + half - 1) >> shift
+		} else if v > math.MaxInt64-half {
+			return max
+		}
+		return (v + half) >> shift
+	case RoundNearestEven:
+		r, rem := v>>shift, v&mask
+		if (rem > half || (rem == half && r&1 == 1)) && r < max {
+			r++
+		}
+		return r
+	case RoundTruncate:
+		if v < 0 {
+			return (v + mask) >> shift
+		}
+		return v >> shift
+	default:
+		return v >> shift
+	}
+}
+
+// fromFloat converts a floating point value in [-1..1] to an integer sample with the given
+// number of bits.
+func fromFloat[T Sample](v float64, bits int, encoding Encoding, rounding Rounding) T {
+	if v != v { // NaN
+		v = 0
+	}
+	x, _ := quantize(math.Ldexp(v, bits-1), bits, rounding)
+	return fromInt[T](x, bits, encoding)
+}
+
+// quantize rounds v, in units of the least significant bit, to a signed value with the given
+// number of bits. If v doesn't fit, the value is saturated and ok is false.
+func quantize(v float64, bits int, rounding Rounding) (x int64, ok bool) {
+	switch rounding {
+	case RoundNearest:
+		v = math.Round(v)
+	case RoundNearestEven:
+		v = math.RoundToEven(v)
+	case RoundTruncate:
+		v = math.Trunc(v)
+	default:
+		v = math.Floor(v)
+	}
+
+	limit := math.Ldexp(1, bits-1)
+	switch {
+	case v >= limit:
+		return int64(uint64(1)<<(bits-1) - 1), false
+	case v < -limit:
+		return -1 << (bits - 1), false
+	default:
+		return int64(v), true
+	}
+}
+
+// fromInt converts a signed value with the given number of bits to an integer sample.
+func fromInt[T Sample](x int64, bits int, encoding Encoding) T {
+	if encoding == Unsigned {
+		return T(uint64(x) ^ (1 << (bits - 1)))
+	}
+	return T(x)
+}
+
+// ToInt16 converts samples in s to []int16 in dst.
+//
+// The provided buffer dst should be nil, or adequately sized to fit all samples.
+func (s Samples[T]) ToInt16(dst []int16) []int16 {
+	return Convert(Samples[int16](dst), s)
+}
+
 // ToFloat converts samples in s to []float64 in dst.
 //
 // The provided buffer dst should be nil, or adequately sized to fit all samples.
@@ -216,7 +396,7 @@ func (b Buffer[T]) ToFloat(dst [][]float64) [][]float64 {
 	}
 
 	for i := range b {
-		Samples[T](b[0]).ToFloat(dst[i])
+		Samples[T](b[i]).ToFloat(dst[i])
 	}
 
 	return dst
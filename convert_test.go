@@ -0,0 +1,196 @@
+package audio_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+)
+
+func TestConvertMatrix(t *testing.T) {
+	testConvertFrom[int](t)
+	testConvertFrom[int8](t)
+	testConvertFrom[int16](t)
+	testConvertFrom[int32](t)
+	testConvertFrom[int64](t)
+	testConvertFrom[uint](t)
+	testConvertFrom[uint8](t)
+	testConvertFrom[uint16](t)
+	testConvertFrom[uint32](t)
+	testConvertFrom[uint64](t)
+	testConvertFrom[float32](t)
+	testConvertFrom[float64](t)
+}
+
+func testConvertFrom[S audio.Sample](t *testing.T) {
+	t.Helper()
+	testConvertPair[S, int](t)
+	testConvertPair[S, int8](t)
+	testConvertPair[S, int16](t)
+	testConvertPair[S, int32](t)
+	testConvertPair[S, int64](t)
+	testConvertPair[S, uint](t)
+	testConvertPair[S, uint8](t)
+	testConvertPair[S, uint16](t)
+	testConvertPair[S, uint32](t)
+	testConvertPair[S, uint64](t)
+	testConvertPair[S, float32](t)
+	testConvertPair[S, float64](t)
+}
+
+func testConvertPair[S, D audio.Sample](t *testing.T) {
+	t.Helper()
+	t.Run(fmt.Sprintf("%T to %T", S(0), D(0)), func(it *testing.T) {
+		var (
+			src  = testFullScale[S]()
+			dst  = audio.Convert[S, D](nil, src)
+			back = audio.Convert[D, S](nil, dst)
+		)
+
+		// Full scale minimum and midpoint must map exactly.
+		if v, want := testNormal(dst[0]), -1.0; v != want {
+			it.Errorf("expected minimum %v to convert to %v, got %v", src[0], want, v)
+		}
+		if v := testNormal(dst[1]); v != 0 {
+			it.Errorf("expected midpoint %v to convert to 0, got %v", src[1], v)
+		}
+
+		lossless := testPrecision[S]() <= testPrecision[D]() && !testIsFloat[S]() || testIsFloat[S]() && testIsFloat[D]() && testPrecision[S]() <= testPrecision[D]()
+		tolerance := math.Ldexp(1, 1-testPrecision[D]())
+		for i, v := range back {
+			if lossless {
+				if v != src[i] {
+					it.Errorf("expected %v to round trip via %T, got %v", src[i], D(0), v)
+				}
+			} else if d := math.Abs(testNormal(v) - testNormal(src[i])); d > tolerance {
+				it.Errorf("expected %v to round trip via %T within %g, got %v (off by %g)", src[i], D(0), tolerance, v, d)
+			}
+		}
+	})
+}
+
+func TestConvertSaturate(t *testing.T) {
+	test := audio.Convert[float64, int16](nil, audio.Samples[float64]{-2, -1, 1, 2, math.Inf(1), math.NaN()})
+	want := audio.Samples[int16]{math.MinInt16, math.MinInt16, math.MaxInt16, math.MaxInt16, math.MaxInt16, 0}
+	for i, v := range test {
+		if v != want[i] {
+			t.Errorf("expected value %d to be %d, got %d", i, want[i], v)
+		}
+	}
+
+	testUnsigned := audio.Convert[float32, uint8](nil, audio.Samples[float32]{-2, 0, 2})
+	wantUnsigned := audio.Samples[uint8]{0, 128, 255}
+	for i, v := range testUnsigned {
+		if v != wantUnsigned[i] {
+			t.Errorf("expected value %d to be %d, got %d", i, wantUnsigned[i], v)
+		}
+	}
+}
+
+func TestConvertRounding(t *testing.T) {
+	// Values are multiples of a quarter int8 LSB.
+	src := audio.Samples[int16]{0x0140, 0x0180, 0x0280, 0x01c0, -0x0140, -0x0180, -0x0280, 0x7fff}
+	testCases := []struct {
+		Rounding audio.Rounding
+		Want     audio.Samples[int8]
+	}{
+		{audio.RoundNearest, audio.Samples[int8]{1, 2, 3, 2, -1, -2, -3, 127}},
+		{audio.RoundNearestEven, audio.Samples[int8]{1, 2, 2, 2, -1, -2, -2, 127}},
+		{audio.RoundFloor, audio.Samples[int8]{1, 1, 2, 1, -2, -2, -3, 127}},
+		{audio.RoundTruncate, audio.Samples[int8]{1, 1, 2, 1, -1, -1, -2, 127}},
+	}
+	for _, test := range testCases {
+		t.Run(test.Rounding.String(), func(it *testing.T) {
+			c := audio.Converter[int16, int8]{Rounding: test.Rounding}
+			for i, v := range c.Convert(nil, src) {
+				if v != test.Want[i] {
+					it.Errorf("expected %#04x to round to %d, got %d", src[i], test.Want[i], v)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertBuffer(t *testing.T) {
+	src := audio.Buffer[uint8]{{0, 128, 255}, {64, 192, 128}}
+	dst := audio.ConvertBuffer[uint8, float32](nil, src)
+	want := audio.Buffer[float32]{{-1, 0, 127.0 / 128}, {-0.5, 0.5, 0}}
+	for c := range want {
+		for i, v := range dst[c] {
+			if v != want[c][i] {
+				t.Errorf("expected channel %d sample %d to be %g, got %g", c, i, want[c][i], v)
+			}
+		}
+	}
+}
+
+func TestToInt16(t *testing.T) {
+	test := audio.Samples[float64]{-1, -0.5, 0, 0.5, 1}.ToInt16(nil)
+	want := []int16{math.MinInt16, -0x4000, 0, 0x4000, math.MaxInt16}
+	for i, v := range test {
+		if v != want[i] {
+			t.Errorf("expected value %d to be %d, got %d", i, want[i], v)
+		}
+	}
+}
+
+// testFullScale returns the minimum, midpoint, maximum and some random values for T.
+func testFullScale[T audio.Sample]() audio.Samples[T] {
+	var (
+		rng  = rand.New(rand.NewPCG(1, 2))
+		bits = audio.Samples[T]{}.BitsPerSample()
+		s    audio.Samples[T]
+	)
+	switch {
+	case testIsFloat[T]():
+		for _, v := range []float64{-1, 0, 1, -0.5, 0.25, 1.0 / 3} {
+			s = append(s, T(v))
+		}
+		for i := 0; i < 16; i++ {
+			s = append(s, T(rng.Float64()*2-1))
+		}
+	case T(0)-1 > 0: // unsigned
+		var (
+			mid = uint64(1) << (bits - 1)
+			max = ^uint64(0) >> (64 - bits)
+		)
+		s = audio.Samples[T]{0, T(mid), T(max), 1, T(mid - 1), T(mid + 1), T(max - 1)}
+		for i := 0; i < 16; i++ {
+			s = append(s, T(rng.Uint64()>>(64-bits)))
+		}
+	default:
+		var (
+			min = int64(-1) << (bits - 1)
+			max = int64(^uint64(0) >> (65 - bits))
+		)
+		s = audio.Samples[T]{T(min), 0, T(max), T(min + 1), T(max - 1), 1}
+		for i := 0; i < 16; i++ {
+			s = append(s, T(int64(rng.Uint64())>>(64-bits)))
+		}
+	}
+	return s
+}
+
+// testNormal scales v to [-1..1].
+func testNormal[T audio.Sample](v T) float64 {
+	return audio.Convert[T, float64](nil, audio.Samples[T]{v})[0]
+}
+
+func testIsFloat[T audio.Sample]() bool {
+	half := 0.5
+	return T(half) != 0
+}
+
+// testPrecision is the number of significant bits of T.
+func testPrecision[T audio.Sample]() int {
+	switch any(T(0)).(type) {
+	case float32:
+		return 24
+	case float64:
+		return 53
+	default:
+		return audio.Samples[T]{}.BitsPerSample()
+	}
+}
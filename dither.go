@@ -0,0 +1,113 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// Dither is the type of noise added to samples before reducing their bit depth, which trades
+// the distortion caused by requantization for a constant noise floor.
+type Dither int
+
+const (
+	NoDither Dither = iota
+
+	// RectangularDither adds noise with a rectangular probability density function of ±0.5 LSB.
+	RectangularDither
+
+	// TriangularDither adds noise with a triangular probability density function (TPDF) of
+	// ±1 LSB, which also makes the noise floor independent of the signal.
+	TriangularDither
+)
+
+func (d Dither) String() string {
+	switch d {
+	case NoDither:
+		return "none"
+	case RectangularDither:
+		return "rectangular"
+	case TriangularDither:
+		return "triangular"
+	default:
+		return fmt.Sprintf("unknown dither %d", d)
+	}
+}
+
+// Noise shaping filters that move the quantization noise to frequencies where the ear is less
+// sensitive, for use in Converter.Shaping. Both are designed for a sample rate of 44.1 kHz.
+var (
+	// LipshitzShaping is the 5 tap E-weighted filter by Lipshitz, Vanderkooy and Wannamaker.
+	LipshitzShaping = []float64{2.033, -2.165, 1.959, -1.590, 0.6149}
+
+	// FWeightedShaping is the 9 tap F-weighted filter by Wannamaker.
+	FWeightedShaping = []float64{2.412, -3.370, 3.937, -4.174, 3.353, -2.205, 1.281, -0.569, 0.0847}
+)
+
+// convertDither converts src to dst with dither and noise shaping.
+func (c *Converter[S, D]) convertDither(dst Samples[D], src Samples[S], channel, channels int) {
+	var (
+		srcBits, srcEncoding = Samples[S]{}.BitsPerSample(), encodingOf[S]()
+		dstBits, dstEncoding = Samples[D]{}.BitsPerSample(), encodingOf[D]()
+	)
+
+	for len(c.errors) < channel+channels {
+		c.errors = append(c.errors, nil)
+	}
+	for ch := channel; ch < channel+channels; ch++ {
+		// Shaping may have changed since the errors were allocated.
+		if len(c.errors[ch]) != len(c.Shaping) {
+			c.errors[ch] = make([]float64, len(c.Shaping))
+		}
+	}
+
+	for i, v := range src {
+		var x float64
+		if srcEncoding == Float {
+			x = float64(v)
+			if x != x { // NaN
+				x = 0
+			}
+		} else {
+			x = float64(toFixed(v, srcBits, srcEncoding)) / fixedToFloat
+		}
+
+		// Scale to units of the least significant bit and subtract the filtered error.
+		x = math.Ldexp(x, dstBits-1)
+		errors := c.errors[channel+i%channels]
+		for k, e := range errors {
+			x -= c.Shaping[k] * e
+		}
+
+		q, ok := quantize(x+c.noise(), dstBits, c.Rounding)
+		if len(errors) > 0 {
+			copy(errors[1:], errors)
+			if ok {
+				errors[0] = float64(q) - x
+			} else {
+				// Don't feed back clipping, it makes the filter unstable.
+				errors[0] = 0
+			}
+		}
+		dst[i] = fromInt[D](q, dstBits, dstEncoding)
+	}
+}
+
+// noise returns a dither value in units of the least significant bit.
+func (c *Converter[S, D]) noise() float64 {
+	switch c.Dither {
+	case RectangularDither:
+		return c.float64() - 0.5
+	case TriangularDither:
+		return c.float64() - c.float64()
+	default:
+		return 0
+	}
+}
+
+func (c *Converter[S, D]) float64() float64 {
+	if c.Rand == nil {
+		return rand.Float64()
+	}
+	return c.Rand.Float64()
+}
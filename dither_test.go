@@ -0,0 +1,147 @@
+package audio_test
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/dsp"
+	"github.com/BeatGlow/audio/dsp/fourier"
+)
+
+const testDitherSamples = 8192
+
+// testSine returns a sine wave with the given amplitude and a frequency of cycles per testDitherSamples.
+func testSine(amplitude float64, cycles int) audio.Samples[float64] {
+	s := make(audio.Samples[float64], testDitherSamples)
+	for i := range s {
+		s[i] = amplitude * math.Sin(2*math.Pi*float64(cycles*i)/testDitherSamples)
+	}
+	return s
+}
+
+// testError returns the requantization error of the converted samples, in units of the int16 LSB.
+func testError(src audio.Samples[float64], dst audio.Samples[int16]) []float64 {
+	e := make([]float64, len(src))
+	for i, v := range dst {
+		e[i] = float64(v) - src[i]*(1<<15)
+	}
+	return e
+}
+
+// testSNR returns the signal to noise ratio in dB of a full scale signal.
+func testSNR(e []float64) float64 {
+	var noise float64
+	for _, v := range e {
+		noise += v * v
+	}
+	// Full scale sine power is half the square of the peak amplitude.
+	signal := float64(1<<15) * float64(1<<15) / 2
+	return 10 * math.Log10(signal/(noise/float64(len(e))))
+}
+
+func TestDitherSQNR(t *testing.T) {
+	var (
+		src  = testSine(1-1.0/(1<<15), 441)
+		sqnr = dsp.SQNR(16)
+	)
+
+	plain := audio.Converter[float64, int16]{}
+	if v := testSNR(testError(src, plain.Convert(nil, src))); v < sqnr {
+		t.Errorf("expected undithered SNR of at least %.2f dB, got %.2f dB", sqnr, v)
+	}
+
+	// TPDF dither adds twice the quantization noise power, which is ~4.77 dB more noise.
+	tpdf := audio.Converter[float64, int16]{Dither: audio.TriangularDither, Rand: rand.New(rand.NewPCG(1, 2))}
+	want := sqnr + 1.76 - 4.77
+	if v := testSNR(testError(src, tpdf.Convert(nil, src))); math.Abs(v-want) > 0.5 {
+		t.Errorf("expected TPDF dithered SNR of %.2f dB, got %.2f dB", want, v)
+	}
+}
+
+func TestDitherDeterministic(t *testing.T) {
+	var (
+		src = testSine(0.001, 10)
+		a   = audio.Converter[float64, int16]{Dither: audio.TriangularDither, Rand: rand.New(rand.NewPCG(42, 0))}
+		b   = audio.Converter[float64, int16]{Dither: audio.TriangularDither, Rand: rand.New(rand.NewPCG(42, 0))}
+	)
+	x, y := a.Convert(nil, src), b.Convert(nil, src)
+	for i := range x {
+		if x[i] != y[i] {
+			t.Fatalf("expected sample %d to be equal with the same seed, got %d and %d", i, x[i], y[i])
+		}
+	}
+}
+
+func TestDitherQuietSignal(t *testing.T) {
+	// A sine well below one LSB disappears without dither.
+	src := testSine(0.3/(1<<15), 64)
+
+	plain := audio.Converter[float64, int16]{}
+	for i, v := range plain.Convert(nil, src) {
+		if v != 0 {
+			t.Fatalf("expected undithered sample %d to be 0, got %d", i, v)
+		}
+	}
+
+	for _, dither := range []audio.Dither{audio.RectangularDither, audio.TriangularDither} {
+		t.Run(dither.String(), func(it *testing.T) {
+			c := audio.Converter[float64, int16]{Dither: dither, Rand: rand.New(rand.NewPCG(1, 2))}
+			var correlation float64
+			for i, v := range c.Convert(nil, src) {
+				correlation += float64(v) * src[i]
+			}
+			if correlation <= 0 {
+				it.Errorf("expected dithered output to correlate with the input, got %g", correlation)
+			}
+		})
+	}
+}
+
+func TestDitherNoiseShaping(t *testing.T) {
+	var (
+		src  = testSine(0.25, 441)
+		tpdf = audio.Converter[float64, int16]{
+			Dither: audio.TriangularDither,
+			Rand:   rand.New(rand.NewPCG(1, 2)),
+		}
+		shaped = audio.Converter[float64, int16]{
+			Dither:  audio.TriangularDither,
+			Shaping: audio.LipshitzShaping,
+			Rand:    rand.New(rand.NewPCG(1, 2)),
+		}
+	)
+
+	// Compare the noise power in the lowest 1/16th of the spectrum.
+	lowBand := func(e []float64) float64 {
+		var power float64
+		for _, v := range fourier.FFT(fourier.ToComplex(e))[1 : testDitherSamples/32] {
+			power += cmplx.Abs(v) * cmplx.Abs(v)
+		}
+		return power
+	}
+
+	plain := lowBand(testError(src, tpdf.Convert(nil, src)))
+	noise := lowBand(testError(src, shaped.Convert(nil, src)))
+	if noise >= plain/4 {
+		t.Errorf("expected noise shaping to lower the low band noise by at least 6 dB, got %.2f dB", 10*math.Log10(noise/plain))
+	}
+}
+
+func TestDitherShapingChange(t *testing.T) {
+	c := audio.Converter[float64, int16]{
+		Dither:  audio.TriangularDither,
+		Shaping: audio.LipshitzShaping,
+		Rand:    rand.New(rand.NewPCG(1, 2)),
+	}
+	src := testSine(0.25, 441)[:64]
+	c.Convert(nil, src)
+
+	// Changing to a longer or shorter filter must not panic.
+	c.Shaping = audio.FWeightedShaping
+	c.Convert(nil, src)
+	c.Shaping = nil
+	c.Convert(nil, src)
+}
@@ -1,8 +1,13 @@
 package alsa
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"runtime"
+	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/BeatGlow/audio"
@@ -39,6 +44,8 @@ type Device struct {
 	hwParams hwParams
 	swParams swParams
 	ready    bool
+
+	readDeadline, writeDeadline time.Time
 }
 
 func (dev Device) String() string {
@@ -51,10 +58,20 @@ func (dev *Device) Close() error {
 
 func (dev *Device) Open() error {
 	var err error
-	if dev.fh, err = os.OpenFile(dev.Path, os.O_RDWR, 0644); err != nil {
+	// Open non-blocking, so transfers wait in the runtime poller and can be interrupted.
+	if dev.fh, err = os.OpenFile(dev.Path, os.O_RDWR|syscall.O_NONBLOCK, 0644); err != nil {
+		return err
+	}
+	// Fd would switch the file back to blocking mode, get the descriptor from the raw conn.
+	conn, err := dev.fh.SyscallConn()
+	if err != nil {
+		_ = dev.fh.Close()
+		return err
+	}
+	if err = conn.Control(func(fd uintptr) { dev.fd = fd }); err != nil {
+		_ = dev.fh.Close()
 		return err
 	}
-	dev.fd = dev.fh.Fd()
 
 	/*
 		if err = ioctl(dev.fd, ioctlEncodePointer(cmdRead, &dev.version, cmdPCMVersion), uintptr(unsafe.Pointer(&dev.version))); err != nil {
@@ -128,61 +145,152 @@ func (dev *Device) prepare() error {
 	return nil
 }
 
-type format struct {
-	sampleFormat
-	channels int
-	rate     float64
-}
-
-func (f format) Channels() int       { return f.channels }
-func (f format) SampleRate() float64 { return f.rate }
-
 func (dev *Device) BufferFormat() (audio.Format, error) {
 	if !dev.ready {
 		if err := dev.prepare(); err != nil {
-			return format{}, err
+			return audio.Format{}, err
 		}
 	}
 
-	var s sampleFormat
-	for s = formatTypeFirst; s <= formatTypeLast; s++ {
-		if s >= formatInt24 && s <= formatUint24BE {
-			// Go doesn't have a native 24-bit type, skip (for now)
-			continue
+	s := Unknown
+	for f := formatTypeFirst; f <= formatTypeLast && s == Unknown; f++ {
+		if dev.hwParams.GetFormatSupport(f) {
+			s = f
 		}
-		if dev.hwParams.GetFormatSupport(s) {
-			break
+	}
+	for f := formatPackedFirst; f <= formatPackedLast && s == Unknown; f++ {
+		if dev.hwParams.GetFormatSupport(f) {
+			s = f
 		}
 	}
+	if s == Unknown {
+		return audio.Format{}, fmt.Errorf("alsa: %s has no supported sample format", dev.Name)
+	}
 
 	ch, _ := dev.hwParams.IntervalRange(paramChannels)
 	rt, _ := dev.hwParams.IntervalRange(paramRate)
 
-	return format{
-		sampleFormat: s,
-		channels:     int(ch),
-		rate:         float64(rt),
-	}, nil
+	return s.Format(int(ch), int(rt)), nil
 }
 
+// Read reads interleaved frames into p, it returns the number of bytes read.
 func (dev *Device) Read(p []byte) (n int, err error) {
-	n = len(p) / dev.bytesPerFrame()
-	x := xferI{
-		Buf:    uintptr(unsafe.Pointer(&p[0])),
-		Frames: uFrames(n),
-	}
-	_ = x
-	return
-	//return n, ioctl(dev.fd, ioctlEncodePointer(cmdRead, &x, cmdPCMReadIFrames), uintptr(unsafe.Pointer(&x)))
+	return dev.ReadContext(context.Background(), p)
+}
+
+// ReadContext is like Read, but stops when ctx is done.
+func (dev *Device) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+	return dev.transfer(ctx, p, false)
 }
 
+// Write writes interleaved frames from p, it returns the number of bytes written.
 func (dev *Device) Write(p []byte) (n int, err error) {
-	n = len(p) / dev.bytesPerFrame()
+	return dev.WriteContext(context.Background(), p)
+}
+
+// WriteContext is like Write, but stops when ctx is done.
+func (dev *Device) WriteContext(ctx context.Context, p []byte) (n int, err error) {
+	return dev.transfer(ctx, p, true)
+}
+
+// SetDeadline sets the read and write deadlines of an open device, a zero value disables the
+// deadlines. Reads and writes that are blocked past the deadline return os.ErrDeadlineExceeded.
+func (dev *Device) SetDeadline(t time.Time) error {
+	if err := dev.fh.SetDeadline(t); err != nil {
+		return err
+	}
+	dev.readDeadline, dev.writeDeadline = t, t
+	return nil
+}
+
+// SetReadDeadline sets the read deadline of an open device.
+func (dev *Device) SetReadDeadline(t time.Time) error {
+	if err := dev.fh.SetReadDeadline(t); err != nil {
+		return err
+	}
+	dev.readDeadline = t
+	return nil
+}
+
+// SetWriteDeadline sets the write deadline of an open device.
+func (dev *Device) SetWriteDeadline(t time.Time) error {
+	if err := dev.fh.SetWriteDeadline(t); err != nil {
+		return err
+	}
+	dev.writeDeadline = t
+	return nil
+}
+
+// aLongTimeAgo is a deadline in the past, which interrupts blocked transfers.
+var aLongTimeAgo = time.Unix(1, 0)
+
+// transfer frames with the device opened in non-blocking mode, the runtime poller waits until
+// the device is ready, so transfers are interrupted by deadlines and by ctx.
+func (dev *Device) transfer(ctx context.Context, p []byte, write bool) (n int, err error) {
+	if err = ctx.Err(); err != nil {
+		return 0, err
+	}
+	if n = len(p) / dev.bytesPerFrame(); n == 0 {
+		return 0, nil
+	}
+
+	conn, err := dev.fh.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	wait, set, deadline := conn.Read, dev.fh.SetReadDeadline, dev.readDeadline
+	if write {
+		wait, set, deadline = conn.Write, dev.fh.SetWriteDeadline, dev.writeDeadline
+	}
+
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		if err = set(d); err != nil {
+			return 0, err
+		}
+	}
+	done := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		_ = set(aLongTimeAgo)
+		close(done)
+	})
+	defer func() {
+		if !stop() {
+			<-done
+		}
+		_ = set(deadline)
+		if errors.Is(err, os.ErrDeadlineExceeded) && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+	}()
+
 	x := xferI{
 		Buf:    uintptr(unsafe.Pointer(&p[0])),
 		Frames: uFrames(n),
 	}
-	_ = x
-	return
-	//return n, ioctl(dev.fd, ioctlEncodePointer(cmdWrite, &x, cmdPCMWriteIFrames), uintptr(unsafe.Pointer(&x)))
+	if werr := wait(func(fd uintptr) bool {
+		err = transferFrames(fd, &x, write)
+		// Let the poller wait until the device is ready.
+		return !errors.Is(err, syscall.EAGAIN)
+	}); werr != nil {
+		return 0, werr
+	}
+	runtime.KeepAlive(p)
+	if err != nil {
+		return 0, err
+	}
+	return int(x.Result) * dev.bytesPerFrame(), nil
+}
+
+// transferFrames reads or writes the frames of x with the device file descriptor fd.
+var transferFrames = func(fd uintptr, x *xferI, write bool) error {
+	return ioctl(fd, transferCommand(x, write), x)
+}
+
+// transferCommand is the ioctl command to read or write the frames of x. The kernel defines
+// writes as _IOW and reads as _IOR.
+func transferCommand(x *xferI, write bool) ioctlCommand {
+	if write {
+		return ioctlPointer(cmdWrite, x, cmdPCMWriteIFrames)
+	}
+	return ioctlPointer(cmdRead, x, cmdPCMReadIFrames)
 }
@@ -0,0 +1,119 @@
+package alsa
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// testDevice returns a stereo 16-bit device that reads from a pipe, with transfers that read the
+// pipe like the ioctl reads the device.
+func testDevice(t *testing.T) (*Device, *os.File) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = r.Close()
+		_ = w.Close()
+	})
+
+	transfer := transferFrames
+	t.Cleanup(func() { transferFrames = transfer })
+	transferFrames = func(fd uintptr, x *xferI, write bool) error {
+		// The samples aren't checked, so they are read into a scratch buffer.
+		p := make([]byte, int(x.Frames)*4)
+		n, err := syscall.Read(int(fd), p)
+		if err != nil {
+			return err
+		}
+		x.Result = sFrames(n / 4)
+		return nil
+	}
+
+	dev := &Device{fh: r}
+	dev.hwParams.Intervals[paramSampleBits-paramFirstInterval].Max = 16
+	dev.hwParams.Intervals[paramChannels-paramFirstInterval].Max = 2
+	return dev, w
+}
+
+func TestTransferCommand(t *testing.T) {
+	// SNDRV_PCM_IOCTL_WRITEI_FRAMES and SNDRV_PCM_IOCTL_READI_FRAMES from the kernel headers.
+	read, write := ioctlCommand(0x80184151), ioctlCommand(0x40184150)
+	if strconv.IntSize == 32 {
+		read, write = 0x800c4151, 0x400c4150
+	}
+	if cmd := transferCommand(new(xferI), false); cmd != read {
+		t.Errorf("expected read %#x, got %#x", uintptr(read), uintptr(cmd))
+	}
+	if cmd := transferCommand(new(xferI), true); cmd != write {
+		t.Errorf("expected write %#x, got %#x", uintptr(write), uintptr(cmd))
+	}
+}
+
+func TestDeviceRead(t *testing.T) {
+	dev, w := testDevice(t)
+	if _, err := w.Write([]byte{1, 2, 3, 4, 5, 6, 7, 8}); err != nil {
+		t.Fatal(err)
+	}
+	p := make([]byte, 16)
+	n, err := dev.Read(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 8 {
+		t.Errorf("expected 8 bytes, got %d", n)
+	}
+}
+
+func TestDeviceDeadline(t *testing.T) {
+	dev, w := testDevice(t)
+	if err := dev.SetReadDeadline(time.Now().Add(10 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	p := make([]byte, 4)
+	if _, err := dev.Read(p); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", os.ErrDeadlineExceeded, err)
+	}
+
+	// Reads work again after the deadline is cleared.
+	if err := dev.SetReadDeadline(time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := dev.Read(p); err != nil || n != 4 {
+		t.Errorf("expected 4 bytes, got %d (%v)", n, err)
+	}
+}
+
+func TestDeviceContext(t *testing.T) {
+	dev, w := testDevice(t)
+	p := make([]byte, 4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := dev.ReadContext(ctx, p); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := dev.ReadContext(ctx, p); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// The context doesn't leave a deadline behind.
+	if _, err := w.Write([]byte{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := dev.Read(p); err != nil || n != 4 {
+		t.Errorf("expected 4 bytes, got %d (%v)", n, err)
+	}
+}
@@ -78,7 +78,7 @@ func ioctl(fd uintptr, command ioctlCommand, ptr interface{}) error {
 	//fmt.Printf("%s :: %d bytes\n", c, reflect.TypeOf(ptr).Elem().Size())
 	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(command), p)
 	if e != 0 {
-		return fmt.Errorf("ioctl %s failed: %v", command, e)
+		return fmt.Errorf("ioctl %s failed: %w", command, e)
 	}
 	return nil
 }
@@ -78,14 +78,18 @@ func (p *hwParams) SetAccess(a Access) {
 	p.SetMask(paramAccess, uint32(1<<uint(a)))
 }
 func (p *hwParams) SetFormat(f sampleFormat) {
-	p.SetMask(paramFormat, uint32(1<<uint(f)))
+	bits := &p.Masks[paramFormat-paramFirstMask].Bits
+	for i := range bits {
+		bits[i] = 0
+	}
+	bits[f/32] = 1 << uint(f%32)
 }
 func (p *hwParams) SetMask(param param, v uint32) {
 	p.Masks[param-paramFirstMask].Bits[0] = v
 }
 func (p *hwParams) GetFormatSupport(f sampleFormat) bool {
-	bits := p.Masks[paramFormat-paramFirstMask].Bits[0]
-	b := bits & (1 << uint(f))
+	bits := p.Masks[paramFormat-paramFirstMask].Bits[f/32]
+	b := bits & (1 << uint(f%32))
 	return b != 0
 }
 
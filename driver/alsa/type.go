@@ -1,8 +1,11 @@
 package alsa
 
 import (
+	"encoding/binary"
 	"fmt"
 	"strings"
+
+	"github.com/BeatGlow/audio"
 )
 
 type Access int
@@ -63,6 +66,16 @@ const (
 	formatTypeLast  = formatFloat64BE
 )
 
+// Packed 24-bit formats, 3 bytes per sample.
+const (
+	formatInt24Packed sampleFormat = iota + 32
+	formatInt24PackedBE
+	formatUint24Packed
+	formatUint24PackedBE
+	formatPackedFirst = formatInt24Packed
+	formatPackedLast  = formatUint24PackedBE
+)
+
 func (f sampleFormat) BitsPerSample() int {
 	switch f {
 	case formatInt8,
@@ -76,7 +89,11 @@ func (f sampleFormat) BitsPerSample() int {
 	case formatInt24,
 		formatInt24BE,
 		formatUint24,
-		formatUint24BE:
+		formatUint24BE,
+		formatInt24Packed,
+		formatInt24PackedBE,
+		formatUint24Packed,
+		formatUint24PackedBE:
 		return 24
 	case formatInt32,
 		formatInt32BE,
@@ -93,6 +110,54 @@ func (f sampleFormat) BitsPerSample() int {
 	}
 }
 
+// Format returns the audio format for samples in this sample format.
+func (f sampleFormat) Format(channels, rate int) audio.Format {
+	format := audio.Format{
+		SampleRate: rate,
+		Channels:   channels,
+		Bits:       f.BitsPerSample(),
+	}
+
+	switch f {
+	case formatInt8,
+		formatInt16, formatInt16BE,
+		formatInt24, formatInt24BE,
+		formatInt24Packed, formatInt24PackedBE,
+		formatInt32, formatInt32BE:
+		format.Encoding = audio.Signed
+	case formatUint8,
+		formatUint16, formatUint16BE,
+		formatUint24, formatUint24BE,
+		formatUint24Packed, formatUint24PackedBE,
+		formatUint32, formatUint32BE:
+		format.Encoding = audio.Unsigned
+	case formatFloat32, formatFloat32BE,
+		formatFloat64, formatFloat64BE:
+		format.Encoding = audio.Float
+	}
+
+	switch f {
+	case formatInt24, formatInt24BE,
+		formatUint24, formatUint24BE:
+		// 24-bit samples in the least significant bits of 32-bit words.
+		format.Container = 32
+	}
+
+	switch f {
+	case formatInt8, formatUint8:
+	case formatInt16BE, formatUint16BE,
+		formatInt24BE, formatUint24BE,
+		formatInt24PackedBE, formatUint24PackedBE,
+		formatInt32BE, formatUint32BE,
+		formatFloat32BE, formatFloat64BE:
+		format.ByteOrder = binary.BigEndian
+	default:
+		format.ByteOrder = binary.LittleEndian
+	}
+
+	return format
+}
+
 func (f sampleFormat) String() string {
 	switch f {
 	case formatInt8:
@@ -115,6 +180,14 @@ func (f sampleFormat) String() string {
 		return "int24be"
 	case formatUint24BE:
 		return "uint24be"
+	case formatInt24Packed:
+		return "int24packed"
+	case formatUint24Packed:
+		return "uint24packed"
+	case formatInt24PackedBE:
+		return "int24packedbe"
+	case formatUint24PackedBE:
+		return "uint24packedbe"
 	case formatInt32:
 		return "int32"
 	case formatInt32BE:
@@ -6,6 +6,7 @@ import (
 	"github.com/BeatGlow/audio"
 	"github.com/BeatGlow/audio/dsp/fourier"
 	"github.com/BeatGlow/audio/dsp/window"
+	"github.com/BeatGlow/audio/internal/scratch"
 )
 
 // SQNR is the signal-to-quantization-noise ratio.
@@ -21,26 +22,30 @@ type FrequencyPower struct {
 }
 
 type FrequencyPowerCalculator[T audio.Sample] struct {
-	// SampleRate in samples per second.
-	SampleRate int
+	// Format of the analyzed samples, only a single channel is analyzed at a time.
+	Format audio.Format
 
 	// Window function
 	Window window.Window
 
+	// Powers contains the result of the last call to Process.
+	Powers []FrequencyPower
+
 	// buffer gets dynamically allocated
+	mono    audio.Samples[T]
 	samples audio.Samples[float64]
 	complex []complex128
 }
 
-func NewFrequencyPowerCalculator[T audio.Sample](sampleRate int, window window.Window) *FrequencyPowerCalculator[T] {
+func NewFrequencyPowerCalculator[T audio.Sample](format audio.Format, window window.Window) *FrequencyPowerCalculator[T] {
 	return &FrequencyPowerCalculator[T]{
-		SampleRate: sampleRate,
-		Window:     window,
+		Format: format,
+		Window: window,
 	}
 }
 
 // Apply applies a Fast Fourier Transform (FFT) on a slice of float64 `data`,
-// with sample rate `sampleRate`. It returns a slice of FrequencyPower.
+// with the sample rate of the calculator Format. It returns a slice of FrequencyPower.
 func (c *FrequencyPowerCalculator[T]) Apply(dst []FrequencyPower, samples audio.Samples[T]) []FrequencyPower {
 	var length = len(samples)
 	if dst == nil {
@@ -68,10 +73,43 @@ func (c *FrequencyPowerCalculator[T]) Apply(dst []FrequencyPower, samples audio.
 		// using math.Sqrt(re*re + im*im) is faster than using math.Hypot(re, im)
 		// see fft_test.go for details
 		dst[i-1] = FrequencyPower{
-			Frequency: i * c.SampleRate / length,
+			Frequency: i * c.Format.SampleRate / length,
 			Magnitude: math.Sqrt(freqReal*freqReal + freqImag*freqImag),
 		}
 	}
 
 	return dst
 }
+
+// Process calculates the frequency powers of the samples into Powers, without modifying the
+// samples. Multiple channels are mixed down to mono first.
+//
+// The number of frames should be a power of two.
+func (c *FrequencyPowerCalculator[T]) Process(samples audio.Samples[T]) error {
+	mono := samples
+	if channels := c.Format.Channels; channels > 1 {
+		frames := len(samples) / channels
+		c.mono = scratch.Grow(c.mono, frames)
+		mono = c.mono
+		for i := range mono {
+			var total float64
+			for _, v := range samples[i*channels : i*channels+channels] {
+				total += float64(v)
+			}
+			mono[i] = T(total / float64(channels))
+		}
+	}
+
+	if len(mono) < 4 {
+		return nil
+	}
+	if want := len(mono)/2 - 1; len(c.Powers) != want {
+		c.Powers = make([]FrequencyPower, want)
+	}
+	c.Powers = c.Apply(c.Powers, mono)
+	return nil
+}
+
+func (c *FrequencyPowerCalculator[T]) String() string {
+	return "frequency power"
+}
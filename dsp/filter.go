@@ -1,6 +1,11 @@
 package dsp
 
-import "github.com/BeatGlow/audio"
+import (
+	"math"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/scratch"
+)
 
 // DCRemoval removes the DC component from a signal.
 func DCRemval[T audio.Sample](signal []T) {
@@ -18,3 +23,49 @@ func DCRemval[T audio.Sample](signal []T) {
 		signal[i] -= mean
 	}
 }
+
+// DCBlocker removes the DC component from a stream of interleaved samples.
+//
+// Unlike DCRemval, which removes the mean of a single block, it uses a one pole high pass filter
+// that keeps its state between blocks.
+type DCBlocker[T audio.Sample] struct {
+	format audio.Format
+	pole   float64
+	x, y   []float64
+	buffer audio.Samples[float64]
+}
+
+// NewDCBlocker returns a DC blocker for samples in the given format, with a cut off at
+// approximately 10 Hz.
+func NewDCBlocker[T audio.Sample](format audio.Format) (*DCBlocker[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	return &DCBlocker[T]{
+		format: format,
+		pole:   1 - 2*math.Pi*10/float64(format.SampleRate),
+		x:      make([]float64, format.Channels),
+		y:      make([]float64, format.Channels),
+	}, nil
+}
+
+func (b *DCBlocker[T]) String() string {
+	return "dc blocker"
+}
+
+// Process removes the DC component of the samples in place.
+func (b *DCBlocker[T]) Process(samples audio.Samples[T]) error {
+	b.buffer = scratch.Grow(b.buffer, len(samples))
+	buffer := audio.Convert(b.buffer, samples)
+
+	channels := b.format.Channels
+	for i, v := range buffer {
+		c := i % channels
+		b.y[c] = v - b.x[c] + b.pole*b.y[c]
+		b.x[c] = v
+		buffer[i] = b.y[c]
+	}
+
+	audio.Convert(samples, buffer)
+	return nil
+}
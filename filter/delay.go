@@ -1,9 +1,9 @@
 package filter
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"math"
 	"time"
 
 	"github.com/BeatGlow/audio"
@@ -12,6 +12,7 @@ import (
 var (
 	ErrChannels      = errors.New("filter: need more than 0 channels")
 	ErrDelayNegative = errors.New("filter: delay can't be negative")
+	ErrCoefficients  = errors.New("filter: need at least one coefficient")
 )
 
 // Delay is a general purpose delay line.
@@ -19,13 +20,18 @@ type Delay[T audio.Sample] struct {
 	audio.Reader[T]
 	audio.Samples[T]
 	time.Duration
+
+	format audio.Format
 }
 
-// NewDelay introduces a fixed delay for reading samples from r.
-func NewDelay[T audio.Sample](r audio.Reader[T], channels, sampleRate int, delay time.Duration) (audio.Reader[T], error) {
-	if channels < 1 {
+// NewDelay introduces a fixed delay for reading samples in the given format from r.
+func NewDelay[T audio.Sample](r audio.Reader[T], format audio.Format, delay time.Duration) (audio.Reader[T], error) {
+	if format.Channels < 1 {
 		return nil, ErrChannels
 	}
+	if format.SampleRate < 1 {
+		return nil, audio.ErrSampleRate
+	}
 
 	if delay < 0 {
 		return nil, ErrDelayNegative
@@ -33,28 +39,39 @@ func NewDelay[T audio.Sample](r audio.Reader[T], channels, sampleRate int, delay
 		return r, nil
 	}
 
-	samplesPerDelay := channels * int(math.Round(float64(sampleRate)*float64(delay)/float64(time.Second)))
 	return &Delay[T]{
 		Reader:   r,
-		Samples:  make(audio.Samples[T], samplesPerDelay),
+		Samples:  make(audio.Samples[T], format.Samples(delay)),
 		Duration: delay,
+		format:   format,
 	}, nil
 }
 
+// Format of the delayed samples.
+func (d *Delay[T]) Format() audio.Format {
+	return d.format
+}
+
 func (d *Delay[T]) String() string {
 	return fmt.Sprintf("delay %s", d.Duration)
 }
 
 func (d *Delay[T]) ReadSamples(buffer audio.Samples[T]) (int, error) {
+	return d.ReadSamplesContext(context.Background(), buffer)
+}
+
+// ReadSamplesContext is like ReadSamples, but stops when ctx is done if the Reader is an
+// audio.ContextReader.
+func (d *Delay[T]) ReadSamplesContext(ctx context.Context, buffer audio.Samples[T]) (int, error) {
 	if len(d.Samples) == 0 {
-		return d.Reader.ReadSamples(buffer)
+		return audio.ReadSamplesContext(ctx, d.Reader, buffer)
 	}
 
 	// Consume delay line samples.
 	n := copy(buffer, d.Samples)
 	if r := len(buffer) - n; r > 0 {
 		// We need more, consume from Reader.
-		if _, err := d.Reader.ReadSamples(buffer[n:]); err != nil {
+		if _, err := audio.ReadSamplesContext(ctx, d.Reader, buffer[n:]); err != nil {
 			return n, err
 		}
 	}
@@ -64,7 +81,7 @@ func (d *Delay[T]) ReadSamples(buffer audio.Samples[T]) (int, error) {
 
 	// Replenish our delay line by consuming samples from Reader.
 	if n > 0 {
-		if _, err := d.Reader.ReadSamples(d.Samples[len(d.Samples)-n:]); err != nil {
+		if _, err := audio.ReadSamplesContext(ctx, d.Reader, d.Samples[len(d.Samples)-n:]); err != nil {
 			return n, err
 		}
 	}
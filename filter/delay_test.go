@@ -2,6 +2,9 @@ package filter
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"os"
 	"testing"
 	"time"
 
@@ -17,8 +20,12 @@ func TestDelayShorterThanBufferLength(t *testing.T) {
 	wantData := make([]byte, 1024)
 	copy(wantData[80:], testData) // 20ms equals 80 samples, which is less than our buffer length of 128
 
-	reader := audio.NewReader[byte](bytes.NewBuffer(testData), nil)
-	d, err := NewDelay(reader, 1, 8000, 10*time.Millisecond)
+	format := audio.FormatOf[byte](8000, 1, nil)
+	reader, err := audio.NewReader[byte](bytes.NewBuffer(testData), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDelay[byte](reader, format, 10*time.Millisecond)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -49,8 +56,12 @@ func TestDelayEqualToBufferLength(t *testing.T) {
 	wantData := make([]byte, 1024)
 	copy(wantData[128:], testData) // 16ms equals 128 samples, which is equal to our buffer length of 128
 
-	reader := audio.NewReader[byte](bytes.NewBuffer(testData), nil)
-	d, err := NewDelay(reader, 1, 8000, 16*time.Millisecond)
+	format := audio.FormatOf[byte](8000, 1, nil)
+	reader, err := audio.NewReader[byte](bytes.NewBuffer(testData), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDelay[byte](reader, format, 16*time.Millisecond)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -81,8 +92,12 @@ func TestDelayLongerThanBufferLength(t *testing.T) {
 	wantData := make([]byte, 1024)
 	copy(wantData[160:], testData) // 20ms equals 160 samples, which is more than our buffer length of 128
 
-	reader := audio.NewReader[byte](bytes.NewBuffer(testData), nil)
-	d, err := NewDelay(reader, 1, 8000, 20*time.Millisecond)
+	format := audio.FormatOf[byte](8000, 1, nil)
+	reader, err := audio.NewReader[byte](bytes.NewBuffer(testData), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDelay[byte](reader, format, 20*time.Millisecond)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -103,3 +118,28 @@ func TestDelayLongerThanBufferLength(t *testing.T) {
 		}
 	}
 }
+
+func TestDelayContext(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.Close()
+	defer pw.Close()
+
+	format := audio.FormatOf[byte](8000, 1, nil)
+	reader, err := audio.NewReader[byte](pr, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDelay[byte](reader, format, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err = audio.ReadSamplesContext(ctx, d, make(audio.Samples[byte], 128)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
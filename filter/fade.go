@@ -0,0 +1,422 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/scratch"
+)
+
+var ErrFadeNegative = errors.New("filter: fade duration can't be negative")
+
+// Curve is the shape of a fade.
+type Curve int
+
+const (
+	// LinearCurve changes the amplitude linearly, which sounds like a dip halfway a crossfade.
+	LinearCurve Curve = iota
+
+	// EqualPowerCurve is a quarter sine, which keeps the power constant during a crossfade of
+	// uncorrelated sources.
+	EqualPowerCurve
+
+	// SCurve is a raised cosine, which starts and ends slowly.
+	SCurve
+
+	// LogCurve changes the level linearly in dB, from -60 dB.
+	LogCurve
+)
+
+func (c Curve) String() string {
+	switch c {
+	case LinearCurve:
+		return "linear"
+	case EqualPowerCurve:
+		return "equal power"
+	case SCurve:
+		return "s-curve"
+	case LogCurve:
+		return "logarithmic"
+	default:
+		return fmt.Sprintf("unknown curve %d", c)
+	}
+}
+
+// Gain returns the fade in gain at position x in [0..1], the fade out gain is Gain(1-x).
+func (c Curve) Gain(x float64) float64 {
+	x = math.Max(0, math.Min(1, x))
+	switch c {
+	case EqualPowerCurve:
+		return math.Sin(x * math.Pi / 2)
+	case SCurve:
+		return (1 - math.Cos(x*math.Pi)) / 2
+	case LogCurve:
+		return (math.Pow(10, 3*(x-1)) - 1e-3) / (1 - 1e-3)
+	default:
+		return x
+	}
+}
+
+// Fade fades samples read from a Reader in or out.
+type Fade[T audio.Sample] struct {
+	reader   audio.Reader[T]
+	format   audio.Format
+	curve    audio.Samples[float64]
+	duration time.Duration
+	out      bool
+	pos      int
+	buffer   audio.Samples[float64]
+}
+
+// NewFadeIn fades in samples in the given format read from r.
+func NewFadeIn[T audio.Sample](r audio.Reader[T], format audio.Format, duration time.Duration, curve Curve) (*Fade[T], error) {
+	return newFade(r, format, duration, curve, false)
+}
+
+// NewFadeOut fades out samples in the given format read from r, and ends after the fade.
+func NewFadeOut[T audio.Sample](r audio.Reader[T], format audio.Format, duration time.Duration, curve Curve) (*Fade[T], error) {
+	return newFade(r, format, duration, curve, true)
+}
+
+func newFade[T audio.Sample](r audio.Reader[T], format audio.Format, duration time.Duration, curve Curve, out bool) (*Fade[T], error) {
+	if format.Channels < 1 {
+		return nil, ErrChannels
+	}
+	if format.SampleRate < 1 {
+		return nil, audio.ErrSampleRate
+	}
+	if duration < 0 {
+		return nil, ErrFadeNegative
+	}
+
+	return &Fade[T]{
+		reader:   r,
+		format:   format,
+		curve:    fadeCurve(curve, format.Frames(duration), out),
+		duration: duration,
+		out:      out,
+	}, nil
+}
+
+// fadeCurve returns the gain of each frame of a fade.
+func fadeCurve(curve Curve, frames int, out bool) audio.Samples[float64] {
+	gains := make(audio.Samples[float64], frames)
+	for i := range gains {
+		x := float64(i) / float64(frames)
+		if out {
+			x = 1 - x
+		}
+		gains[i] = curve.Gain(x)
+	}
+	return gains
+}
+
+// Format of the faded samples.
+func (f *Fade[T]) Format() audio.Format {
+	return f.format
+}
+
+func (f *Fade[T]) String() string {
+	if f.out {
+		return fmt.Sprintf("fade out %s", f.duration)
+	}
+	return fmt.Sprintf("fade in %s", f.duration)
+}
+
+func (f *Fade[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	channels := f.format.Channels
+	if f.pos >= len(f.curve) {
+		if f.out {
+			return 0, io.EOF
+		}
+		return f.reader.ReadSamples(samples)
+	}
+
+	// Don't read past the end of a fade out.
+	if f.out {
+		samples = samples[:min(len(samples), (len(f.curve)-f.pos)*channels)]
+	}
+
+	n, err := f.reader.ReadSamples(samples)
+	f.buffer = scratch.Grow(f.buffer, n)
+	audio.Convert(f.buffer, samples[:n])
+	for i := range f.buffer {
+		frame := f.pos + i/channels
+		if frame < len(f.curve) {
+			f.buffer[i] *= f.curve[frame]
+		}
+	}
+	audio.Convert(samples, f.buffer)
+	f.pos += n / channels
+	return n, err
+}
+
+// Crossfade fades out one Reader while fading in another, and continues reading from the other.
+type Crossfade[T audio.Sample] struct {
+	from, to audio.Reader[T]
+	format   audio.Format
+	in, out  audio.Samples[float64]
+	duration time.Duration
+	pos      int
+	a, b     audio.Samples[T]
+	fa, fb   audio.Samples[float64]
+	toEnded  bool
+}
+
+// NewCrossfade fades samples in the given format read from from into samples read from to.
+func NewCrossfade[T audio.Sample](from, to audio.Reader[T], format audio.Format, duration time.Duration, curve Curve) (*Crossfade[T], error) {
+	if format.Channels < 1 {
+		return nil, ErrChannels
+	}
+	if format.SampleRate < 1 {
+		return nil, audio.ErrSampleRate
+	}
+	if duration < 0 {
+		return nil, ErrFadeNegative
+	}
+
+	frames := format.Frames(duration)
+	return &Crossfade[T]{
+		from:     from,
+		to:       to,
+		format:   format,
+		in:       fadeCurve(curve, frames, false),
+		out:      fadeCurve(curve, frames, true),
+		duration: duration,
+	}, nil
+}
+
+// Format of the samples.
+func (c *Crossfade[T]) Format() audio.Format {
+	return c.format
+}
+
+func (c *Crossfade[T]) String() string {
+	return fmt.Sprintf("crossfade %s", c.duration)
+}
+
+// ReadSamples reads the faded samples. If the Reader faded to ends during the fade, the rest of
+// the Reader faded from passes through. Samples read before an error are returned with it.
+func (c *Crossfade[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	channels := c.format.Channels
+	if c.toEnded {
+		return c.from.ReadSamples(samples)
+	}
+	if c.pos >= len(c.in) {
+		return c.to.ReadSamples(samples)
+	}
+
+	samples = samples[:min(len(samples)-len(samples)%channels, (len(c.in)-c.pos)*channels)]
+	if len(samples) == 0 {
+		return 0, io.ErrShortBuffer
+	}
+	c.a = scratch.Grow(c.a, len(samples))
+	c.b = scratch.Grow(c.b, len(samples))
+	na, err := readFull(c.from, c.a)
+	if err == io.EOF {
+		err = nil
+	} else if err != nil {
+		// Only fade the frames read before the error.
+		if na -= na % channels; na == 0 {
+			return 0, err
+		}
+		samples = samples[:na]
+	}
+	nb, errTo := readFull(c.to, c.b[:len(samples)])
+	if errTo == io.EOF {
+		c.toEnded = nb < len(samples)
+	} else if errTo != nil && err == nil {
+		err = errTo
+	}
+	clear(c.a[na:len(samples)])
+	clear(c.b[nb:len(samples)])
+
+	c.fa = scratch.Grow(c.fa, len(samples))
+	c.fb = scratch.Grow(c.fb, len(samples))
+	audio.Convert(c.fa, c.a)
+	audio.Convert(c.fb, c.b)
+	for i := range c.fa {
+		frame := c.pos + i/channels
+		switch {
+		case i < nb:
+			c.fa[i] = c.fa[i]*c.out[frame] + c.fb[i]*c.in[frame]
+		case !c.toEnded:
+			// The samples of the Reader faded to are missing after an error.
+			c.fa[i] *= c.out[frame]
+		}
+	}
+	audio.Convert(samples, c.fa)
+
+	n := len(samples)
+	if na < n && nb < n {
+		// Both Readers ended, or one ended and the other failed.
+		n = max(na, nb)
+		n -= n % channels
+	}
+	c.pos += n / channels
+	if n == 0 && err == nil {
+		return 0, io.EOF
+	}
+	return n, err
+}
+
+// Concat reads from sources back to back, optionally overlapping them with a crossfade.
+type Concat[T audio.Sample] struct {
+	sources []audio.Reader[T]
+	format  audio.Format
+	overlap int
+	curve   Curve
+	ended   bool
+
+	// held contains frames of the current source that have been read but not returned, the
+	// last overlap frames are kept until the source ends.
+	held   audio.Samples[float64]
+	buffer audio.Samples[T]
+	next   audio.Samples[float64]
+}
+
+// NewConcat reads samples in the given format from each source in turn. If overlap is positive,
+// the end of each source is crossfaded with the start of the next source.
+func NewConcat[T audio.Sample](format audio.Format, overlap time.Duration, curve Curve, sources ...audio.Reader[T]) (*Concat[T], error) {
+	if format.Channels < 1 {
+		return nil, ErrChannels
+	}
+	if format.SampleRate < 1 {
+		return nil, audio.ErrSampleRate
+	}
+	if overlap < 0 {
+		return nil, ErrFadeNegative
+	}
+
+	return &Concat[T]{
+		sources: sources,
+		format:  format,
+		overlap: format.Frames(overlap),
+		curve:   curve,
+	}, nil
+}
+
+// Format of the samples.
+func (c *Concat[T]) Format() audio.Format {
+	return c.format
+}
+
+func (c *Concat[T]) String() string {
+	return fmt.Sprintf("concat %d sources", len(c.sources))
+}
+
+func (c *Concat[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	channels := c.format.Channels
+	samples = samples[:len(samples)-len(samples)%channels]
+	if len(samples) == 0 {
+		return 0, io.ErrShortBuffer
+	}
+
+	for len(c.sources) > 0 {
+		if !c.ended {
+			// Read enough to return samples and still hold back the overlap.
+			if err := c.fill(len(samples) + c.overlap*channels); err != nil {
+				return 0, err
+			}
+		}
+
+		// Hold back the overlap, unless this is the last source.
+		hold := c.overlap * channels
+		if c.ended && len(c.sources) == 1 {
+			hold = 0
+		}
+		if n := len(c.held) - hold; n > 0 {
+			return c.emit(samples, n), nil
+		}
+		if len(c.sources) == 1 {
+			c.sources = nil
+			break
+		}
+
+		// The current source has ended, crossfade its tail with the next source.
+		c.sources = c.sources[1:]
+		if err := c.crossfade(); err != nil {
+			return 0, err
+		}
+	}
+	return 0, io.EOF
+}
+
+// fill reads from the current source until held contains size samples or the source ends.
+func (c *Concat[T]) fill(size int) error {
+	if len(c.held) >= size {
+		return nil
+	}
+	c.buffer = scratch.Grow(c.buffer, size-len(c.held))
+	n, err := readFull(c.sources[0], c.buffer)
+	n -= n % c.format.Channels
+	if err == io.EOF {
+		c.ended = true
+	} else if err != nil {
+		return err
+	}
+
+	offset := len(c.held)
+	c.held = slices.Grow(c.held, n)[:offset+n]
+	audio.Convert(c.held[offset:], c.buffer[:n])
+	return nil
+}
+
+// crossfade mixes the held tail of the previous source with the start of the current source.
+func (c *Concat[T]) crossfade() error {
+	c.ended = false
+	if len(c.held) == 0 {
+		return nil
+	}
+
+	channels := c.format.Channels
+	c.buffer = scratch.Grow(c.buffer, len(c.held))
+	n, err := readFull(c.sources[0], c.buffer)
+	n -= n % channels
+	if err == io.EOF {
+		c.ended = true
+	} else if err != nil {
+		return err
+	}
+	c.next = scratch.Grow(c.next, n)
+	audio.Convert(c.next, c.buffer[:n])
+
+	frames := len(c.held) / channels
+	for i := range c.held {
+		x := float64(i/channels) / float64(frames)
+		c.held[i] *= c.curve.Gain(1 - x)
+		if i < n {
+			c.held[i] += c.next[i] * c.curve.Gain(x)
+		}
+	}
+	return nil
+}
+
+// emit moves up to n held samples to samples.
+func (c *Concat[T]) emit(samples audio.Samples[T], n int) int {
+	n = min(n, len(samples))
+	audio.Convert(samples[:n], c.held[:n])
+	c.held = c.held[:copy(c.held, c.held[n:])]
+	return n
+}
+
+// readFull reads from r until samples are full or an error occurs.
+func readFull[T audio.Sample](r audio.Reader[T], samples audio.Samples[T]) (int, error) {
+	var n int
+	for n < len(samples) {
+		m, err := r.ReadSamples(samples[n:])
+		n += m
+		if err != nil {
+			return n, err
+		}
+		if m == 0 {
+			return n, io.ErrNoProgress
+		}
+	}
+	return n, nil
+}
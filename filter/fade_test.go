@@ -0,0 +1,157 @@
+package filter
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/BeatGlow/audio"
+)
+
+// testSamples is a Reader of a fixed number of samples.
+type testSamples audio.Samples[float64]
+
+func (s *testSamples) ReadSamples(samples audio.Samples[float64]) (int, error) {
+	if len(*s) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(samples, *s)
+	*s = (*s)[n:]
+	return n, nil
+}
+
+// testError is a Reader of a fixed number of samples, followed by an error.
+type testError struct {
+	testSamples
+	err error
+}
+
+func (s *testError) ReadSamples(samples audio.Samples[float64]) (int, error) {
+	n, err := s.testSamples.ReadSamples(samples)
+	if err == io.EOF {
+		err = s.err
+	}
+	return n, err
+}
+
+// testReadAll reads all samples from r, using small reads.
+func testReadAll(t *testing.T, r audio.Reader[float64]) audio.Samples[float64] {
+	t.Helper()
+	var (
+		all    audio.Samples[float64]
+		buffer = make(audio.Samples[float64], 3)
+	)
+	for {
+		n, err := r.ReadSamples(buffer)
+		all = append(all, buffer[:n]...)
+		if err == io.EOF {
+			return all
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testEqual(t *testing.T, test, want audio.Samples[float64]) {
+	t.Helper()
+	if len(test) != len(want) {
+		t.Fatalf("expected %d samples, got %d: %v", len(want), len(test), test)
+	}
+	for i, v := range want {
+		if math.Abs(test[i]-v) > 1e-9 {
+			t.Errorf("expected sample %d to be %g, got %g", i, v, test[i])
+		}
+	}
+}
+
+// testFormat has 1 frame per millisecond.
+var testFormat = audio.FormatOf[float64](1000, 1, binary.LittleEndian)
+
+func TestCurve(t *testing.T) {
+	for _, curve := range []Curve{LinearCurve, EqualPowerCurve, SCurve, LogCurve} {
+		t.Run(curve.String(), func(it *testing.T) {
+			if v := curve.Gain(0); v != 0 {
+				it.Errorf("expected gain 0 at the start, got %g", v)
+			}
+			if v := curve.Gain(1); math.Abs(v-1) > 1e-12 {
+				it.Errorf("expected gain 1 at the end, got %g", v)
+			}
+			for x := 0.01; x <= 1; x += 0.01 {
+				if curve.Gain(x) < curve.Gain(x-0.01) {
+					it.Fatalf("expected gain to increase at %g", x)
+				}
+			}
+		})
+	}
+
+	for x := 0.0; x <= 1; x += 0.1 {
+		if v := math.Pow(EqualPowerCurve.Gain(x), 2) + math.Pow(EqualPowerCurve.Gain(1-x), 2); math.Abs(v-1) > 1e-12 {
+			t.Errorf("expected constant power at %g, got %g", x, v)
+		}
+	}
+}
+
+func TestFade(t *testing.T) {
+	in, err := NewFadeIn[float64](&testSamples{1, 1, 1, 1, 1, 1}, testFormat, 4*time.Millisecond, LinearCurve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testEqual(t, testReadAll(t, in), audio.Samples[float64]{0, 0.25, 0.5, 0.75, 1, 1})
+
+	out, err := NewFadeOut[float64](&testSamples{1, 1, 1, 1, 1, 1}, testFormat, 4*time.Millisecond, LinearCurve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testEqual(t, testReadAll(t, out), audio.Samples[float64]{1, 0.75, 0.5, 0.25})
+
+	if _, err = NewFadeIn[float64](nil, testFormat, -time.Second, LinearCurve); err != ErrFadeNegative {
+		t.Errorf("expected error %q, got %q", ErrFadeNegative, err)
+	}
+}
+
+func TestCrossfade(t *testing.T) {
+	c, err := NewCrossfade[float64](&testSamples{1, 1, 1, 1, 1, 1}, &testSamples{0.5, 0.5, 0.5, 0.5, 0.5, 0.5}, testFormat, 4*time.Millisecond, LinearCurve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testEqual(t, testReadAll(t, c), audio.Samples[float64]{1, 0.875, 0.75, 0.625, 0.5, 0.5})
+
+	// The rest of the Reader faded from passes through if the other ends during the fade.
+	c, err = NewCrossfade[float64](&testSamples{1, 1, 1, 1, 1, 1}, &testSamples{0.5, 0.5}, testFormat, 4*time.Millisecond, LinearCurve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testEqual(t, testReadAll(t, c), audio.Samples[float64]{1, 0.875, 1, 1, 1, 1})
+
+	// The faded frames are returned with an error.
+	c, err = NewCrossfade[float64](&testSamples{1, 1, 1, 1}, &testError{testSamples{0.5}, io.ErrUnexpectedEOF}, testFormat, 4*time.Millisecond, LinearCurve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make(audio.Samples[float64], 3)
+	n, err := c.ReadSamples(samples)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("expected %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+	testEqual(t, samples[:n], audio.Samples[float64]{1, 0.75, 0.5})
+}
+
+func TestConcat(t *testing.T) {
+	c, err := NewConcat[float64](testFormat, 0, LinearCurve, &testSamples{1, 2}, &testSamples{}, &testSamples{3, 4, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testEqual(t, testReadAll(t, c), audio.Samples[float64]{1, 2, 3, 4, 5})
+
+	// Each source overlaps the next by 2 frames.
+	c, err = NewConcat[float64](testFormat, 2*time.Millisecond, LinearCurve,
+		&testSamples{1, 1, 1, 1},
+		&testSamples{2, 2, 2, 2},
+		&testSamples{3, 3, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testEqual(t, testReadAll(t, c), audio.Samples[float64]{1, 1, 1, 1.5, 2, 2.5, 3})
+}
@@ -1,8 +1,12 @@
 package filter
 
 import (
+	"fmt"
+
+	"github.com/BeatGlow/audio"
 	"github.com/BeatGlow/audio/dsp/filter"
 	"github.com/BeatGlow/audio/dsp/window"
+	"github.com/BeatGlow/audio/internal/scratch"
 )
 
 // LowPass is a basic LowPass filter cutting off
@@ -38,3 +42,121 @@ func HighPass(dst, src []float64, cutOffFrequency float64, sampleRate int) []flo
 	fir := &filter.FIR{Sinc: s}
 	return fir.HighPass(dst, src)
 }
+
+// FIR is a streaming finite impulse response filter for interleaved samples.
+//
+// Unlike LowPass and HighPass, it keeps the history of each channel between calls to Process, so
+// the filter can be applied to a stream of blocks.
+type FIR[T audio.Sample] struct {
+	format       audio.Format
+	name         string
+	coefficients []float64
+
+	// history contains the last len(coefficients)-1 input values, per channel.
+	history [][]float64
+	input   audio.Samples[float64]
+	output  audio.Samples[float64]
+}
+
+// NewFIR returns a streaming filter with the given coefficients for samples in the given format.
+func NewFIR[T audio.Sample](format audio.Format, name string, coefficients []float64) (*FIR[T], error) {
+	if format.Channels < 1 {
+		return nil, ErrChannels
+	}
+	if len(coefficients) == 0 {
+		return nil, ErrCoefficients
+	}
+
+	history := make([][]float64, format.Channels)
+	for i := range history {
+		history[i] = make([]float64, len(coefficients)-1)
+	}
+	return &FIR[T]{
+		format:       format,
+		name:         name,
+		coefficients: coefficients,
+		history:      history,
+	}, nil
+}
+
+// NewLowPass returns a streaming low pass filter for samples in the given format.
+func NewLowPass[T audio.Sample](format audio.Format, cutOffFrequency float64) (*FIR[T], error) {
+	if format.SampleRate < 1 {
+		return nil, audio.ErrSampleRate
+	}
+	s := &filter.Sinc{
+		Taps:            62,
+		SampleRate:      format.SampleRate,
+		CutOffFrequency: cutOffFrequency,
+		Window:          window.Hamming,
+	}
+	return NewFIR[T](format, fmt.Sprintf("low pass %g Hz", cutOffFrequency), s.LowPassCoefficients())
+}
+
+// NewHighPass returns a streaming high pass filter for samples in the given format.
+func NewHighPass[T audio.Sample](format audio.Format, cutOffFrequency float64) (*FIR[T], error) {
+	if format.SampleRate < 1 {
+		return nil, audio.ErrSampleRate
+	}
+	s := &filter.Sinc{
+		Taps:            62,
+		SampleRate:      format.SampleRate,
+		CutOffFrequency: cutOffFrequency,
+		Window:          window.Blackman,
+	}
+	return NewFIR[T](format, fmt.Sprintf("high pass %g Hz", cutOffFrequency), s.HighPassCoefficients())
+}
+
+func (f *FIR[T]) String() string {
+	return f.name
+}
+
+// Format of the filtered samples.
+func (f *FIR[T]) Format() audio.Format {
+	return f.format
+}
+
+// Process filters the samples in place.
+func (f *FIR[T]) Process(samples audio.Samples[T]) error {
+	var (
+		channels = f.format.Channels
+		frames   = len(samples) / channels
+		taps     = len(f.coefficients)
+	)
+	if frames == 0 {
+		return nil
+	}
+
+	f.input = audio.Convert(scratch.Grow(f.input, len(samples)), samples)
+	f.output = scratch.Grow(f.output, len(samples))
+
+	for c, history := range f.history {
+		for i := 0; i < frames; i++ {
+			var sum float64
+			for k, h := range f.coefficients {
+				// Input values before the start of this block come from the history.
+				if j := i - k; j >= 0 {
+					sum += h * f.input[j*channels+c]
+				} else {
+					sum += h * history[len(history)+j]
+				}
+			}
+			f.output[i*channels+c] = sum
+		}
+
+		// Keep the most recent input values for the next block.
+		if frames >= taps-1 {
+			for i := range history {
+				history[i] = f.input[(frames-len(history)+i)*channels+c]
+			}
+		} else {
+			copy(history, history[frames:])
+			for i := 0; i < frames; i++ {
+				history[len(history)-frames+i] = f.input[i*channels+c]
+			}
+		}
+	}
+
+	audio.Convert(samples, f.output)
+	return nil
+}
@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+)
+
+func TestFIRStreaming(t *testing.T) {
+	format := audio.FormatOf[float64](8000, 2, binary.LittleEndian)
+
+	// Left channel is a low tone, right channel is a high tone.
+	signal := make(audio.Samples[float64], 1024*2)
+	for i := 0; i < len(signal)/2; i++ {
+		signal[i*2] = 0.5 * math.Sin(2*math.Pi*100*float64(i)/8000)
+		signal[i*2+1] = 0.5 * math.Sin(2*math.Pi*3000*float64(i)/8000)
+	}
+
+	whole, err := NewLowPass[float64](format, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make(audio.Samples[float64], len(signal))
+	copy(want, signal)
+	if err = whole.Process(want); err != nil {
+		t.Fatal(err)
+	}
+
+	// Processing in blocks of varying sizes, including blocks shorter than the filter, must give
+	// the same result.
+	blocks, err := NewLowPass[float64](format, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := make(audio.Samples[float64], len(signal))
+	copy(test, signal)
+	for i, frames := 0, 1; i < len(test); i, frames = i+frames*2, frames*3%97+1 {
+		if err = blocks.Process(test[i:min(i+frames*2, len(test))]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, v := range test {
+		if math.Abs(v-want[i]) > 1e-12 {
+			t.Fatalf("expected sample %d to be %g, got %g", i, want[i], v)
+		}
+	}
+
+	// After the filter settles, the low tone passes and the high tone is attenuated.
+	var left, right float64
+	for i := 128; i < len(want)/2; i++ {
+		left = max(left, math.Abs(want[i*2]))
+		right = max(right, math.Abs(want[i*2+1]))
+	}
+	if left < 0.45 {
+		t.Errorf("expected the low tone to pass, got peak %g", left)
+	}
+	if right > 0.01 {
+		t.Errorf("expected the high tone to be attenuated, got peak %g", right)
+	}
+	if v := whole.String(); v != "low pass 1000 Hz" {
+		t.Errorf("unexpected description %q", v)
+	}
+}
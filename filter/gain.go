@@ -0,0 +1,201 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/scratch"
+)
+
+// Ramp is the shape of a gain change.
+type Ramp int
+
+const (
+	// LinearRamp changes the gain by the same amount every frame.
+	LinearRamp Ramp = iota
+
+	// ExponentialRamp approaches the target with decreasing steps, like an RC filter, which
+	// sounds smoother than a linear ramp. The ramp duration is the time to get within 60 dB of
+	// the target.
+	ExponentialRamp
+)
+
+func (r Ramp) String() string {
+	switch r {
+	case LinearRamp:
+		return "linear"
+	case ExponentialRamp:
+		return "exponential"
+	default:
+		return fmt.Sprintf("unknown ramp %d", r)
+	}
+}
+
+// DefaultRamp is the ramp duration of a new Gain, which is short enough to feel instant and
+// long enough to avoid zipper noise.
+const DefaultRamp = 20 * time.Millisecond
+
+// rampSettled is the difference from the target gain at which an exponential ramp ends.
+const rampSettled = 1e-6
+
+// Gain changes the volume of samples read from a Reader.
+//
+// The gain, mute and ramp can be changed from another goroutine while samples are being read,
+// changes are ramped per frame to avoid clicks.
+type Gain[T audio.Sample] struct {
+	reader audio.Reader[T]
+	format audio.Format
+
+	target atomic.Uint64 // math.Float64bits of the linear gain
+	muted  atomic.Bool
+	ramp   atomic.Int32
+	frames atomic.Int64 // ramp duration in frames
+
+	// Only used by ReadSamples.
+	current float64
+	to      float64
+	shape   Ramp
+	step    float64
+	buffer  audio.Samples[float64]
+}
+
+// NewGain changes the volume of samples in the given format read from r, starting at unity gain.
+func NewGain[T audio.Sample](r audio.Reader[T], format audio.Format) (*Gain[T], error) {
+	if format.Channels < 1 {
+		return nil, ErrChannels
+	}
+	if format.SampleRate < 1 {
+		return nil, audio.ErrSampleRate
+	}
+
+	g := &Gain[T]{
+		reader:  r,
+		format:  format,
+		current: 1,
+		to:      1,
+	}
+	g.target.Store(math.Float64bits(1))
+	g.SetRamp(LinearRamp, DefaultRamp)
+	return g, nil
+}
+
+// Format of the samples.
+func (g *Gain[T]) Format() audio.Format {
+	return g.format
+}
+
+func (g *Gain[T]) String() string {
+	if g.Muted() {
+		return "gain muted"
+	}
+	return fmt.Sprintf("gain %.1f dB", g.Decibels())
+}
+
+// SetLinear sets the target gain as a factor, negative values are treated as 0.
+func (g *Gain[T]) SetLinear(gain float64) {
+	g.target.Store(math.Float64bits(math.Max(0, gain)))
+}
+
+// Linear returns the target gain as a factor.
+func (g *Gain[T]) Linear() float64 {
+	return math.Float64frombits(g.target.Load())
+}
+
+// SetDecibels sets the target gain in dB.
+func (g *Gain[T]) SetDecibels(gain float64) {
+	g.SetLinear(math.Pow(10, gain/20))
+}
+
+// Decibels returns the target gain in dB, which is -Inf for a gain of 0.
+func (g *Gain[T]) Decibels() float64 {
+	return 20 * math.Log10(g.Linear())
+}
+
+// SetMuted mutes or unmutes, without changing the target gain.
+func (g *Gain[T]) SetMuted(muted bool) {
+	g.muted.Store(muted)
+}
+
+// Muted checks if the gain is muted.
+func (g *Gain[T]) Muted() bool {
+	return g.muted.Load()
+}
+
+// SetRamp sets the shape and duration of the following gain changes. A duration of 0 changes
+// the gain immediately.
+func (g *Gain[T]) SetRamp(ramp Ramp, duration time.Duration) {
+	g.ramp.Store(int32(ramp))
+	g.frames.Store(int64(g.format.Frames(duration)))
+}
+
+// ReadSamples reads samples from the Reader and applies the gain.
+func (g *Gain[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	n, err := g.reader.ReadSamples(samples)
+	if n == 0 {
+		return n, err
+	}
+
+	to := g.Linear()
+	if g.Muted() {
+		to = 0
+	}
+	if to != g.to {
+		g.start(to)
+	}
+
+	if g.current == 1 && g.to == 1 {
+		// Fast path, unity gain.
+		return n, err
+	}
+
+	g.buffer = scratch.Grow(g.buffer, n)
+	audio.Convert(g.buffer, samples[:n])
+	channels := g.format.Channels
+	for i := 0; i < n; i += channels {
+		for j := i; j < min(i+channels, n); j++ {
+			g.buffer[j] *= g.current
+		}
+		g.advance()
+	}
+	audio.Convert(samples, g.buffer)
+	return n, err
+}
+
+// start a ramp from the current gain to the target.
+func (g *Gain[T]) start(to float64) {
+	g.to, g.shape = to, Ramp(g.ramp.Load())
+
+	frames := g.frames.Load()
+	if frames < 1 {
+		g.current = to
+		return
+	}
+	switch g.shape {
+	case ExponentialRamp:
+		g.step = 1 - math.Pow(1e-3, 1/float64(frames))
+	default:
+		g.step = (to - g.current) / float64(frames)
+	}
+}
+
+// advance the ramp by one frame.
+func (g *Gain[T]) advance() {
+	if g.current == g.to {
+		return
+	}
+	switch g.shape {
+	case ExponentialRamp:
+		g.current += (g.to - g.current) * g.step
+		if math.Abs(g.to-g.current) < rampSettled {
+			g.current = g.to
+		}
+	default:
+		g.current += g.step
+		if (g.step > 0 && g.current >= g.to) || (g.step <= 0 && g.current <= g.to) {
+			g.current = g.to
+		}
+	}
+}
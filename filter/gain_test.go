@@ -0,0 +1,118 @@
+package filter
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BeatGlow/audio"
+)
+
+// testConstant is an endless stream of samples with the same value.
+type testConstant float64
+
+func (c testConstant) ReadSamples(samples audio.Samples[float64]) (int, error) {
+	for i := range samples {
+		samples[i] = float64(c)
+	}
+	return len(samples), nil
+}
+
+func TestGainRamp(t *testing.T) {
+	format := audio.FormatOf[float64](1000, 2, binary.LittleEndian)
+	testCases := []struct {
+		Ramp Ramp
+		Want []float64 // gain of frames 0, 5 and 10
+	}{
+		{LinearRamp, []float64{1, 0.75, 0.5}},
+		{ExponentialRamp, []float64{1, 0.5 + 0.5*math.Sqrt(1e-3), 0.5 + 0.5*1e-3}},
+	}
+	for _, test := range testCases {
+		t.Run(test.Ramp.String(), func(it *testing.T) {
+			g, err := NewGain[float64](testConstant(1), format)
+			if err != nil {
+				it.Fatal(err)
+			}
+			g.SetRamp(test.Ramp, 10*time.Millisecond)
+			g.SetDecibels(-6.0206)
+
+			samples := make(audio.Samples[float64], 2*20)
+			if _, err = g.ReadSamples(samples); err != nil {
+				it.Fatal(err)
+			}
+			for i, frame := range []int{0, 5, 10} {
+				if v := samples[frame*2]; math.Abs(v-test.Want[i]) > 1e-4 {
+					it.Errorf("expected frame %d to have gain %g, got %g", frame, test.Want[i], v)
+				}
+				if samples[frame*2] != samples[frame*2+1] {
+					it.Errorf("expected all channels in frame %d to have the same gain", frame)
+				}
+			}
+			for i := 2; i < len(samples); i += 2 {
+				if samples[i] > samples[i-2] {
+					it.Fatalf("expected the gain to decrease, frame %d is louder", i/2)
+				}
+			}
+			if v := samples[len(samples)-1]; math.Abs(v-0.5) > 1e-4 {
+				it.Errorf("expected gain to settle at 0.5, got %g", v)
+			}
+		})
+	}
+}
+
+func TestGainMute(t *testing.T) {
+	g, err := NewGain[float64](testConstant(0.5), audio.FormatOf[float64](1000, 1, binary.LittleEndian))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetRamp(LinearRamp, 0)
+	g.SetMuted(true)
+
+	samples := make(audio.Samples[float64], 4)
+	if _, err = g.ReadSamples(samples); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range samples {
+		if v != 0 {
+			t.Errorf("expected muted sample %d to be 0, got %g", i, v)
+		}
+	}
+	if v := g.String(); v != "gain muted" {
+		t.Errorf("unexpected description %q", v)
+	}
+
+	g.SetMuted(false)
+	if _, err = g.ReadSamples(samples); err != nil {
+		t.Fatal(err)
+	}
+	if samples[0] != 0.5 {
+		t.Errorf("expected unmuted sample to be 0.5, got %g", samples[0])
+	}
+}
+
+func TestGainConcurrent(t *testing.T) {
+	g, err := NewGain[float64](testConstant(0.5), audio.FormatOf[float64](48000, 2, binary.LittleEndian))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wait sync.WaitGroup
+	wait.Add(1)
+	go func() {
+		defer wait.Done()
+		for i := 0; i < 1000; i++ {
+			g.SetDecibels(-float64(i % 60))
+			g.SetMuted(i%7 == 0)
+		}
+	}()
+
+	samples := make(audio.Samples[float64], 256)
+	for i := 0; i < 1000; i++ {
+		if _, err = g.ReadSamples(samples); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wait.Wait()
+}
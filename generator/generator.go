@@ -0,0 +1,302 @@
+// Package generator produces test tones and noise.
+//
+// All generators produce the same signal on every channel, with amplitudes relative to full
+// scale. Samples that exceed full scale are saturated when converted to integer samples.
+package generator
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/scratch"
+)
+
+// Tone is a periodic signal.
+type Tone struct {
+	// Frequency in Hz.
+	Frequency float64
+
+	// Amplitude relative to full scale.
+	Amplitude float64
+
+	// Phase at the first frame in radians.
+	Phase float64
+}
+
+func (t Tone) String() string {
+	return fmt.Sprintf("%g Hz at %.1f dBFS", t.Frequency, 20*math.Log10(t.Amplitude))
+}
+
+// Generator is an audio.Reader of a generated signal.
+type Generator[T audio.Sample] struct {
+	format audio.Format
+	name   string
+	next   func() float64
+
+	// frames is the remaining number of frames, or -1 if the signal doesn't end.
+	frames int
+	buffer audio.Samples[float64]
+}
+
+func newGenerator[T audio.Sample](format audio.Format, name string, next func() float64) (*Generator[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	return &Generator[T]{
+		format: format,
+		name:   name,
+		next:   next,
+		frames: -1,
+	}, nil
+}
+
+// Format of the generated samples.
+func (g *Generator[T]) Format() audio.Format {
+	return g.format
+}
+
+func (g *Generator[T]) String() string {
+	return g.name
+}
+
+// ReadSamples generates as many whole frames as fit in samples.
+func (g *Generator[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	var (
+		channels = g.format.Channels
+		frames   = len(samples) / channels
+	)
+	if g.frames >= 0 {
+		if g.frames == 0 {
+			return 0, io.EOF
+		}
+		frames = min(frames, g.frames)
+		g.frames -= frames
+	}
+	if frames == 0 {
+		return 0, io.ErrShortBuffer
+	}
+
+	g.buffer = scratch.Grow(g.buffer, frames*channels)
+	for i := 0; i < len(g.buffer); i += channels {
+		v := g.next()
+		for c := 0; c < channels; c++ {
+			g.buffer[i+c] = v
+		}
+	}
+	audio.Convert(samples, g.buffer)
+	return len(g.buffer), nil
+}
+
+// oscillator advances the phase of a tone, and returns the phase before advancing.
+func oscillator(format audio.Format, tone Tone) func() float64 {
+	var (
+		phase = math.Mod(tone.Phase, 2*math.Pi)
+		step  = 2 * math.Pi * tone.Frequency / float64(format.SampleRate)
+	)
+	return func() float64 {
+		v := phase
+		phase = math.Mod(phase+step, 2*math.Pi)
+		return v
+	}
+}
+
+// Sine generates a sine wave.
+func Sine[T audio.Sample](format audio.Format, tone Tone) (*Generator[T], error) {
+	phase := oscillator(format, tone)
+	return newGenerator[T](format, "sine "+tone.String(), func() float64 {
+		return tone.Amplitude * math.Sin(phase())
+	})
+}
+
+// MultiTone generates the sum of sine waves.
+func MultiTone[T audio.Sample](format audio.Format, tones ...Tone) (*Generator[T], error) {
+	phases := make([]func() float64, len(tones))
+	for i, tone := range tones {
+		phases[i] = oscillator(format, tone)
+	}
+	return newGenerator[T](format, fmt.Sprintf("multi tone %v", tones), func() float64 {
+		var v float64
+		for i, phase := range phases {
+			v += tones[i].Amplitude * math.Sin(phase())
+		}
+		return v
+	})
+}
+
+// harmonics returns the sum of the harmonics of a tone below the Nyquist frequency, where gain
+// returns the amplitude of harmonic k.
+func harmonics(format audio.Format, tone Tone, gain func(k int) float64) func() float64 {
+	var (
+		phase = oscillator(format, tone)
+		count int
+	)
+	if tone.Frequency != 0 {
+		count = int(math.Ceil(float64(format.SampleRate)/2/math.Abs(tone.Frequency))) - 1
+	}
+	return func() float64 {
+		// Compute sin(kθ) with the Chebyshev recurrence.
+		var (
+			theta      = phase()
+			c          = 2 * math.Cos(theta)
+			prev, curr = 0.0, math.Sin(theta)
+			v          float64
+		)
+		for k := 1; k <= count; k++ {
+			v += gain(k) * curr
+			prev, curr = curr, c*curr-prev
+		}
+		return tone.Amplitude * v
+	}
+}
+
+// Square generates a band-limited square wave, which overshoots the amplitude by about 9%
+// around the edges.
+func Square[T audio.Sample](format audio.Format, tone Tone) (*Generator[T], error) {
+	return newGenerator[T](format, "square "+tone.String(), harmonics(format, tone, func(k int) float64 {
+		if k%2 == 0 {
+			return 0
+		}
+		return 4 / (math.Pi * float64(k))
+	}))
+}
+
+// Triangle generates a band-limited triangle wave.
+func Triangle[T audio.Sample](format audio.Format, tone Tone) (*Generator[T], error) {
+	return newGenerator[T](format, "triangle "+tone.String(), harmonics(format, tone, func(k int) float64 {
+		if k%2 == 0 {
+			return 0
+		}
+		v := 8 / (math.Pi * math.Pi * float64(k*k))
+		if k%4 == 3 {
+			return -v
+		}
+		return v
+	}))
+}
+
+// Sawtooth generates a rising band-limited sawtooth wave, which overshoots the amplitude by
+// about 9% around the edges.
+func Sawtooth[T audio.Sample](format audio.Format, tone Tone) (*Generator[T], error) {
+	return newGenerator[T](format, "sawtooth "+tone.String(), harmonics(format, tone, func(k int) float64 {
+		v := 2 / (math.Pi * float64(k))
+		if k%2 == 0 {
+			return -v
+		}
+		return v
+	}))
+}
+
+// LinearSweep generates a sine wave with a frequency that changes linearly from one frequency to
+// another. The sweep ends after duration.
+func LinearSweep[T audio.Sample](format audio.Format, amplitude, from, to float64, duration time.Duration) (*Generator[T], error) {
+	return sweep[T](format, fmt.Sprintf("linear sweep %g to %g Hz", from, to), amplitude, duration, func(t, length float64) float64 {
+		// Phase is the integral of the frequency over time.
+		return 2 * math.Pi * (from*t + (to-from)*t*t/(2*length))
+	})
+}
+
+// LogSweep generates a sine wave with a frequency that changes exponentially from one frequency
+// to another, so each octave takes the same time. The sweep ends after duration.
+func LogSweep[T audio.Sample](format audio.Format, amplitude, from, to float64, duration time.Duration) (*Generator[T], error) {
+	return sweep[T](format, fmt.Sprintf("logarithmic sweep %g to %g Hz", from, to), amplitude, duration, func(t, length float64) float64 {
+		k := math.Log(to / from)
+		if k == 0 {
+			return 2 * math.Pi * from * t
+		}
+		return 2 * math.Pi * from * length / k * (math.Exp(t/length*k) - 1)
+	})
+}
+
+func sweep[T audio.Sample](format audio.Format, name string, amplitude float64, duration time.Duration, phase func(t, length float64) float64) (*Generator[T], error) {
+	var (
+		frame  int
+		length = duration.Seconds()
+	)
+	g, err := newGenerator[T](format, name, func() float64 {
+		t := float64(frame) / float64(format.SampleRate)
+		frame++
+		return amplitude * math.Sin(phase(t, length))
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.frames = format.Frames(duration)
+	return g, nil
+}
+
+// Impulse generates impulses of the given amplitude, separated by period. If period is zero,
+// only the first frame contains an impulse.
+func Impulse[T audio.Sample](format audio.Format, amplitude float64, period time.Duration) (*Generator[T], error) {
+	var (
+		frame  int
+		frames = format.Frames(period)
+	)
+	return newGenerator[T](format, fmt.Sprintf("impulse every %s", period), func() float64 {
+		v := 0.0
+		if frame == 0 {
+			v = amplitude
+		}
+		frame++
+		if frames > 0 && frame == frames {
+			frame = 0
+		}
+		return v
+	})
+}
+
+// WhiteNoise generates uniformly distributed noise with a flat spectrum. Generators with the
+// same seed produce the same noise.
+func WhiteNoise[T audio.Sample](format audio.Format, amplitude float64, seed uint64) (*Generator[T], error) {
+	white := noise(seed)
+	return newGenerator[T](format, "white noise", func() float64 {
+		return amplitude * white()
+	})
+}
+
+// PinkNoise generates noise with equal power per octave, which falls 3 dB per octave. The
+// amplitude is approximate. Generators with the same seed produce the same noise.
+func PinkNoise[T audio.Sample](format audio.Format, amplitude float64, seed uint64) (*Generator[T], error) {
+	var (
+		white = noise(seed)
+		b     [7]float64
+	)
+	return newGenerator[T](format, "pink noise", func() float64 {
+		// Paul Kellet's refined method, accurate to ±0.05 dB above 9 Hz at 44.1 kHz.
+		w := white()
+		b[0] = 0.99886*b[0] + w*0.0555179
+		b[1] = 0.99332*b[1] + w*0.0750759
+		b[2] = 0.96900*b[2] + w*0.1538520
+		b[3] = 0.86650*b[3] + w*0.3104856
+		b[4] = 0.55000*b[4] + w*0.5329522
+		b[5] = -0.7616*b[5] - w*0.0168980
+		v := b[0] + b[1] + b[2] + b[3] + b[4] + b[5] + b[6] + w*0.5362
+		b[6] = w * 0.115926
+		return amplitude * v * 0.11
+	})
+}
+
+// BrownNoise generates noise that falls 6 dB per octave, like a random walk. The amplitude is
+// approximate. Generators with the same seed produce the same noise.
+func BrownNoise[T audio.Sample](format audio.Format, amplitude float64, seed uint64) (*Generator[T], error) {
+	var (
+		white = noise(seed)
+		v     float64
+	)
+	return newGenerator[T](format, "brown noise", func() float64 {
+		// Leaky integrator, to prevent drifting away.
+		v = (v + 0.02*white()) / 1.02
+		return amplitude * v * 3.5
+	})
+}
+
+// noise returns uniformly distributed values in [-1..1).
+func noise(seed uint64) func() float64 {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	return func() float64 {
+		return 2*rng.Float64() - 1
+	}
+}
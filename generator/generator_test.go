@@ -0,0 +1,224 @@
+package generator
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"math/cmplx"
+	"testing"
+	"time"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/dsp/fourier"
+)
+
+var testFormat = audio.FormatOf[float64](8000, 1, binary.LittleEndian)
+
+// testRead reads n samples from r.
+func testRead(t *testing.T, r audio.Reader[float64], n int) audio.Samples[float64] {
+	t.Helper()
+	s := make(audio.Samples[float64], n)
+	if _, err := r.ReadSamples(s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// testLevel returns the level in dBFS of a sine wave at the given frequency, measured with a
+// Blackman window.
+func testLevel(s audio.Samples[float64], frequency float64, sampleRate int) float64 {
+	var (
+		sum  complex128
+		gain float64
+		size = float64(len(s) - 1)
+	)
+	for i, v := range s {
+		w := 0.42 - 0.5*math.Cos(2*math.Pi*float64(i)/size) + 0.08*math.Cos(4*math.Pi*float64(i)/size)
+		sum += complex(v*w, 0) * cmplx.Exp(complex(0, -2*math.Pi*frequency*float64(i)/float64(sampleRate)))
+		gain += w
+	}
+	return 20 * math.Log10(2*cmplx.Abs(sum)/gain)
+}
+
+func TestSine(t *testing.T) {
+	g, err := Sine[float64](testFormat, Tone{Frequency: 1000, Amplitude: 0.5, Phase: math.Pi / 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range testRead(t, g, 16) {
+		if want := 0.5 * math.Cos(math.Pi/4*float64(i)); math.Abs(v-want) > 1e-12 {
+			t.Errorf("expected sample %d to be %g, got %g", i, want, v)
+		}
+	}
+}
+
+func TestMultiTone(t *testing.T) {
+	var (
+		a = Tone{Frequency: 440, Amplitude: 0.25}
+		b = Tone{Frequency: 1000, Amplitude: 0.125, Phase: 1}
+	)
+	g, err := MultiTone[float64](testFormat, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ga, _ := Sine[float64](testFormat, a)
+	gb, _ := Sine[float64](testFormat, b)
+
+	test, sa, sb := testRead(t, g, 64), testRead(t, ga, 64), testRead(t, gb, 64)
+	for i, v := range test {
+		if want := sa[i] + sb[i]; math.Abs(v-want) > 1e-12 {
+			t.Errorf("expected sample %d to be %g, got %g", i, want, v)
+		}
+	}
+}
+
+func TestBandLimited(t *testing.T) {
+	format := audio.FormatOf[float64](44100, 1, binary.LittleEndian)
+	tone := Tone{Frequency: 1000, Amplitude: 0.5}
+	testCases := []struct {
+		Name      string
+		Generator func(audio.Format, Tone) (*Generator[float64], error)
+		Harmonics []float64 // level of the first harmonics in dBFS
+	}{
+		{"square", Square[float64], []float64{20 * math.Log10(2/math.Pi), math.Inf(-1), 20 * math.Log10(2/math.Pi/3)}},
+		{"triangle", Triangle[float64], []float64{20 * math.Log10(4/math.Pi/math.Pi), math.Inf(-1), 20 * math.Log10(4/math.Pi/math.Pi/9)}},
+		{"sawtooth", Sawtooth[float64], []float64{20 * math.Log10(1/math.Pi), 20 * math.Log10(1/math.Pi/2), 20 * math.Log10(1/math.Pi/3)}},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			g, err := test.Generator(format, tone)
+			if err != nil {
+				it.Fatal(err)
+			}
+			s := testRead(it, g, 8192)
+			for k, want := range test.Harmonics {
+				f := tone.Frequency * float64(k+1)
+				if v := testLevel(s, f, 44100); math.IsInf(want, -1) && v > -100 || !math.IsInf(want, -1) && math.Abs(v-want) > 0.01 {
+					it.Errorf("expected harmonic at %g Hz to be %.2f dBFS, got %.2f dBFS", f, want, v)
+				}
+			}
+
+			// A naive waveform would alias its 23rd harmonic to 21100 Hz.
+			if v := testLevel(s, 21100, 44100); v > -100 {
+				it.Errorf("expected no aliasing at 21100 Hz, got %.2f dBFS", v)
+			}
+		})
+	}
+}
+
+func TestSweep(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Generator func(audio.Format, float64, float64, float64, time.Duration) (*Generator[float64], error)
+		Crossings int
+	}{
+		// Zero crossings are twice the average frequency.
+		{"linear", LinearSweep[float64], 2 * 600},
+		{"log", LogSweep[float64], int(2 * 1000 / math.Log(11))},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			g, err := test.Generator(testFormat, 1, 100, 1100, time.Second)
+			if err != nil {
+				it.Fatal(err)
+			}
+
+			var (
+				all    audio.Samples[float64]
+				buffer = make(audio.Samples[float64], 1000)
+			)
+			for {
+				n, err := g.ReadSamples(buffer)
+				all = append(all, buffer[:n]...)
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					it.Fatal(err)
+				}
+			}
+			if len(all) != 8000 {
+				it.Fatalf("expected 8000 samples, got %d", len(all))
+			}
+
+			var crossings int
+			for i := 1; i < len(all); i++ {
+				if (all[i-1] < 0) != (all[i] < 0) {
+					crossings++
+				}
+			}
+			if crossings < test.Crossings-2 || crossings > test.Crossings+2 {
+				it.Errorf("expected %d zero crossings, got %d", test.Crossings, crossings)
+			}
+		})
+	}
+}
+
+func TestImpulse(t *testing.T) {
+	g, err := Impulse[float64](testFormat, 1, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range testRead(t, g, 24) {
+		want := 0.0
+		if i%8 == 0 {
+			want = 1
+		}
+		if v != want {
+			t.Errorf("expected sample %d to be %g, got %g", i, want, v)
+		}
+	}
+}
+
+func TestNoise(t *testing.T) {
+	const size = 1 << 16
+	testCases := []struct {
+		Name      string
+		Generator func(audio.Format, float64, uint64) (*Generator[float64], error)
+		Slope     float64 // power per Hz in dB per octave
+	}{
+		{"white", WhiteNoise[float64], 0},
+		{"pink", PinkNoise[float64], -3},
+		{"brown", BrownNoise[float64], -6},
+	}
+	format := audio.FormatOf[float64](44100, 2, binary.LittleEndian)
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			a, _ := test.Generator(format, 1, 42)
+			b, _ := test.Generator(format, 1, 42)
+			c, _ := test.Generator(format, 1, 43)
+			sa, sb, sc := testRead(it, a, 2*size), testRead(it, b, 2*size), testRead(it, c, 2*size)
+
+			mono := make([]float64, size)
+			for i := range mono {
+				if sa[i] != sb[i] {
+					it.Fatalf("expected the same noise with the same seed at sample %d", i)
+				}
+				if sa[i*2] != sa[i*2+1] {
+					it.Fatalf("expected the same noise on all channels at frame %d", i)
+				}
+				mono[i] = sa[i*2]
+			}
+			if sa[0] == sc[0] && sa[1000] == sc[1000] {
+				it.Error("expected different noise with a different seed")
+			}
+
+			// Compare the power per Hz of the octaves above 344 and 2756 Hz, which are 3 octaves apart.
+			var (
+				spectrum = fourier.FFT(fourier.ToComplex(mono))
+				bin      = 44100.0 / size
+				density  = func(from float64) float64 {
+					var power float64
+					for i := int(from / bin); i < int(2*from/bin); i++ {
+						power += cmplx.Abs(spectrum[i]) * cmplx.Abs(spectrum[i])
+					}
+					return power / (from / bin)
+				}
+				slope = 10 * math.Log10(density(2756)/density(344)) / 3
+			)
+			it.Logf("slope %.2f dB per octave", slope)
+			if math.Abs(slope-test.Slope) > 1 {
+				it.Errorf("expected spectrum to fall %g dB per octave, got %.2f dB", -test.Slope, -slope)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Package audiotest implements helpers for the tests of the file formats.
+package audiotest
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+)
+
+// File is an in-memory io.WriteSeeker.
+type File struct {
+	Data   []byte
+	offset int64
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	if end := int(f.offset) + len(p); end > len(f.Data) {
+		f.Data = append(f.Data, make([]byte, end-len(f.Data))...)
+	}
+	n := copy(f.Data[f.offset:], p)
+	f.offset += int64(n)
+	return n, nil
+}
+
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += int64(len(f.Data))
+	}
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// WriteCloser is a Writer of a file format, which finishes the file when it's closed.
+type WriteCloser[T audio.Sample] interface {
+	audio.Writer[T]
+	io.Closer
+}
+
+// Write writes samples to the WriteCloser that create returns for a File, closes it, and
+// returns the data of the File. Writing after Close is expected to fail with errClosed.
+func Write[T audio.Sample, W WriteCloser[T]](t testing.TB, create func(*File) (W, error), samples audio.Samples[T], errClosed error) []byte {
+	t.Helper()
+	f := new(File)
+	w, err := create(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.WriteSamples(samples); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.WriteSamples(samples); err != errClosed {
+		t.Errorf("WriteSamples after Close: expected %v, got %v", errClosed, err)
+	}
+	return f.Data
+}
+
+// ReadAll reads samples from r until io.EOF, a few samples at a time.
+func ReadAll[T audio.Sample](t testing.TB, r audio.Reader[T]) audio.Samples[T] {
+	t.Helper()
+	var (
+		all    audio.Samples[T]
+		buffer = make(audio.Samples[T], 5)
+	)
+	for {
+		n, err := r.ReadSamples(buffer)
+		all = append(all, buffer[:n]...)
+		if err == io.EOF {
+			return all
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Ramp returns frames of samples for the channels, rising in steps of 1024 from below zero.
+func Ramp(channels, frames int) audio.Samples[int16] {
+	samples := make(audio.Samples[int16], channels*frames)
+	for i := range samples {
+		samples[i] = int16((i - len(samples)/2) * 1024)
+	}
+	return samples
+}
+
+// Near checks that the samples read back from a file match the samples written to it. The
+// samples can differ by 512, since 8-bit and companded samples lose precision.
+func Near(t testing.TB, got, expected audio.Samples[int16]) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d samples, got %d", len(expected), len(got))
+	}
+	for i, v := range got {
+		if diff := int(v) - int(expected[i]); diff < -512 || diff > 512 {
+			t.Errorf("sample %d: expected %d, got %d", i, expected[i], v)
+			return
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package chunk reads and writes the chunks of RIFF and IFF files, like WAVE and AIFF, which only
+// differ in byte order.
+package chunk
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrTooLarge is returned for metadata chunks that are too large to read into memory.
+var ErrTooLarge = errors.New("chunk too large")
+
+// maxBody is the largest chunk body read into memory.
+const maxBody = 1 << 24
+
+// Start returns r as an io.ReadSeeker and an io.ReaderAt if it is both, and the offset where the
+// file starts.
+//
+// The file doesn't necessarily start at the beginning of r, the offsets of the samples are
+// relative to where it starts.
+func Start(r io.Reader) (io.ReadSeeker, io.ReaderAt, int64, error) {
+	seeker, _ := r.(io.ReadSeeker)
+	readerAt, _ := r.(io.ReaderAt)
+	if seeker == nil || readerAt == nil {
+		return nil, nil, 0, nil
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return seeker, readerAt, start, nil
+}
+
+// Padded returns the size of a chunk body including the pad byte for odd sizes.
+func Padded(size int64) int64 {
+	return size + size&1
+}
+
+// ReadHeader reads the ID and size of the next chunk. It returns io.EOF at the end of r, also
+// after a partial header.
+func ReadHeader(r io.Reader, order binary.ByteOrder) (string, int64, error) {
+	var c [8]byte
+	if _, err := io.ReadFull(r, c[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return "", 0, err
+	}
+	return string(c[:4]), int64(order.Uint32(c[4:])), nil
+}
+
+// ReadBody reads a chunk body of size bytes and its pad byte.
+func ReadBody(r io.Reader, id string, size int64) ([]byte, error) {
+	// Metadata chunks are small, but don't trust their size.
+	if size > maxBody {
+		return nil, fmt.Errorf("%w: %q chunk of %d bytes", ErrTooLarge, id, size)
+	}
+	body := make([]byte, Padded(size))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body[:size], nil
+}
+
+// Skip n bytes of r.
+func Skip(r io.Reader, n int64) error {
+	if s, ok := r.(io.Seeker); ok {
+		_, err := s.Seek(n, io.SeekCurrent)
+		return err
+	}
+	_, err := io.CopyN(io.Discard, r, n)
+	return err
+}
+
+// Write a chunk with a pad byte for odd sizes.
+func Write(w io.Writer, order binary.ByteOrder, id string, body []byte) error {
+	header := make([]byte, 8, 8+len(body)+1)
+	copy(header, id)
+	order.PutUint32(header[4:], uint32(len(body)))
+	b := append(header, body...)
+	if len(body)&1 == 1 {
+		b = append(b, 0)
+	}
+	_, err := w.Write(b)
+	return err
+}
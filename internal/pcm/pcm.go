@@ -0,0 +1,181 @@
+// Package pcm reads and writes samples of any type in any format, converting between the sample
+// type of the format and the requested sample type.
+package pcm
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/codec/g711"
+	"github.com/BeatGlow/audio/internal/scratch"
+)
+
+// NewReader returns a Reader for samples in the given format read from r, converted to T.
+func NewReader[T audio.Sample](r io.Reader, format audio.Format) (audio.FormatReader[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	switch format.Encoding {
+	case audio.ALaw, audio.MuLaw:
+		return g711.NewReader[T](r, format)
+	}
+	if rd, err := audio.NewReader[T](r, format); err == nil {
+		return rd, nil
+	}
+
+	switch native(format) {
+	case "uint8":
+		return newReader[uint8, T](r, format)
+	case "int8":
+		return newReader[int8, T](r, format)
+	case "uint16":
+		return newReader[uint16, T](r, format)
+	case "int16":
+		return newReader[int16, T](r, format)
+	case "uint32":
+		return newReader[uint32, T](r, format)
+	case "int32":
+		return newReader[int32, T](r, format)
+	case "uint64":
+		return newReader[uint64, T](r, format)
+	case "int64":
+		return newReader[int64, T](r, format)
+	case "float32":
+		return newReader[float32, T](r, format)
+	case "float64":
+		return newReader[float64, T](r, format)
+	default:
+		return nil, fmt.Errorf("audio: can't read %s", format)
+	}
+}
+
+// NewWriter returns a Writer that converts samples of type T to the given format written to w.
+func NewWriter[T audio.Sample](w io.Writer, format audio.Format) (audio.FormatWriter[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	switch format.Encoding {
+	case audio.ALaw, audio.MuLaw:
+		return g711.NewWriter[T](w, format)
+	}
+	if wr, err := audio.NewWriter[T](w, format); err == nil {
+		return wr, nil
+	}
+
+	switch native(format) {
+	case "uint8":
+		return newWriter[uint8, T](w, format)
+	case "int8":
+		return newWriter[int8, T](w, format)
+	case "uint16":
+		return newWriter[uint16, T](w, format)
+	case "int16":
+		return newWriter[int16, T](w, format)
+	case "uint32":
+		return newWriter[uint32, T](w, format)
+	case "int32":
+		return newWriter[int32, T](w, format)
+	case "uint64":
+		return newWriter[uint64, T](w, format)
+	case "int64":
+		return newWriter[int64, T](w, format)
+	case "float32":
+		return newWriter[float32, T](w, format)
+	case "float64":
+		return newWriter[float64, T](w, format)
+	default:
+		return nil, fmt.Errorf("audio: can't write %s", format)
+	}
+}
+
+// native returns the name of the sample type that can store samples of the format without
+// conversion, 24-bit samples are stored in 32-bit types.
+func native(format audio.Format) string {
+	var kind string
+	switch format.Encoding {
+	case audio.Signed:
+		kind = "int"
+	case audio.Unsigned:
+		kind = "uint"
+	case audio.Float:
+		kind = "float"
+	default:
+		return ""
+	}
+	switch bits := format.BitsPerSample(); {
+	case format.Encoding == audio.Float:
+		return fmt.Sprint(kind, format.Bits)
+	case format.Bits == 24 && bits <= 32:
+		return kind + "32"
+	case format.Bits == bits:
+		return fmt.Sprint(kind, bits)
+	default:
+		return ""
+	}
+}
+
+// reader converts samples read as S to T.
+type reader[S, T audio.Sample] struct {
+	reader audio.Reader[S]
+	format audio.Format
+	buffer audio.Samples[S]
+}
+
+func newReader[S, T audio.Sample](r io.Reader, format audio.Format) (audio.FormatReader[T], error) {
+	rd, err := audio.NewReader[S](r, format)
+	if err != nil {
+		return nil, err
+	}
+	return Convert[S, T](rd, format), nil
+}
+
+// Convert returns a Reader that converts samples in the given format read from r to T.
+func Convert[S, T audio.Sample](r audio.Reader[S], format audio.Format) audio.FormatReader[T] {
+	if rd, ok := r.(audio.FormatReader[T]); ok {
+		return rd
+	}
+	return &reader[S, T]{
+		reader: r,
+		format: format,
+	}
+}
+
+func (r *reader[S, T]) Format() audio.Format {
+	return r.format
+}
+
+func (r *reader[S, T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	r.buffer = scratch.Grow(r.buffer, len(samples))
+	n, err := r.reader.ReadSamples(r.buffer)
+	audio.Convert(samples, r.buffer[:n])
+	return n, err
+}
+
+// SeekFrame seeks if the underlying reader can seek.
+func (r *reader[S, T]) SeekFrame(frame int64, whence int) (int64, error) {
+	if s, ok := r.reader.(audio.FrameSeeker); ok {
+		return s.SeekFrame(frame, whence)
+	}
+	return 0, audio.ErrSeek
+}
+
+// writer converts samples of T to S before writing.
+type writer[S, T audio.Sample] struct {
+	audio.FormatWriter[S]
+	buffer audio.Samples[S]
+}
+
+func newWriter[S, T audio.Sample](w io.Writer, format audio.Format) (audio.FormatWriter[T], error) {
+	wr, err := audio.NewWriter[S](w, format)
+	if err != nil {
+		return nil, err
+	}
+	return &writer[S, T]{FormatWriter: wr}, nil
+}
+
+func (w *writer[S, T]) WriteSamples(samples audio.Samples[T]) (int, error) {
+	w.buffer = scratch.Grow(w.buffer, len(samples))
+	audio.Convert(w.buffer, samples)
+	return w.FormatWriter.WriteSamples(w.buffer)
+}
@@ -0,0 +1,10 @@
+// Package scratch manages the reusable buffers of readers, writers and filters.
+package scratch
+
+// Grow returns s with a length of n, reallocating if required.
+func Grow[S ~[]E, E any](s S, n int) S {
+	if cap(s) < n {
+		return make(S, n)
+	}
+	return s[:n]
+}
@@ -1,8 +1,15 @@
 package audio
 
 import (
+	"context"
 	"encoding/binary"
+	"fmt"
 	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/BeatGlow/audio/internal/scratch"
 )
 
 // Reader can read samples.
@@ -11,21 +18,219 @@ type Reader[T Sample] interface {
 }
 
 type reader[T Sample] struct {
-	r     io.Reader
-	order binary.ByteOrder
+	r        io.Reader
+	format   Format
+	buf      []byte
+	deadline time.Time
+
+	// seeker is set if r can seek, origin is the offset of the first frame.
+	seeker io.Seeker
+	origin int64
+}
+
+// NewReader returns a Reader that can read samples in the given format from any io.Reader.
+func NewReader[T Sample](r io.Reader, format Format) (FormatReader[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	if !canDecode[T](format) {
+		return nil, fmt.Errorf("audio: can't read %s as %T", format, T(0))
+	}
+	rd := &reader[T]{
+		r:      r,
+		format: format,
+	}
+	if s, ok := r.(io.Seeker); ok {
+		// Pipes and sockets implement io.Seeker, but fail to seek.
+		if origin, err := s.Seek(0, io.SeekCurrent); err == nil {
+			rd.seeker, rd.origin = s, origin
+		}
+	}
+	return rd, nil
+}
+
+func (r *reader[T]) Format() Format {
+	return r.format
 }
 
-// NewReader returns a Reader that can read from any io.Reader.
-func NewReader[T Sample](r io.Reader, order binary.ByteOrder) Reader[T] {
-	return reader[T]{
-		r:     r,
-		order: order,
+// ReadSamples reads samples without allocating, the scratch buffer is reused between calls. If
+// the samples are stored in the byte order of the host, they are read without copying.
+func (r *reader[T]) ReadSamples(samples Samples[T]) (int, error) {
+	if !isHostOrder[T](r.format) {
+		r.buf = scratch.Grow(r.buf, len(samples)*r.format.BytesPerSample())
 	}
+	return samples.decodeFromChunked(r.r, r.format, len(samples), r.buf)
 }
 
-func (r reader[T]) ReadSamples(samples Samples[T]) (int, error) {
-	i, err := samples.DecodeFrom(r.r, r.order)
-	return int(i), err
+// ReadSamplesContext is like ReadSamples, but stops when ctx is done. A blocked read can only be
+// interrupted if the io.Reader supports read deadlines, like files, pipes and connections do.
+func (r *reader[T]) ReadSamplesContext(ctx context.Context, samples Samples[T]) (int, error) {
+	var set func(time.Time) error
+	if d, ok := r.r.(readDeadliner); ok {
+		set = d.SetReadDeadline
+	}
+	return withContext(ctx, set, r.deadline, func() (int, error) {
+		return r.ReadSamples(samples)
+	})
+}
+
+// SeekFrame seeks to a frame relative to whence, where frame 0 is where the io.Reader was when
+// the reader was created. It returns the new frame offset, or ErrSeek if the io.Reader can't seek.
+func (r *reader[T]) SeekFrame(frame int64, whence int) (int64, error) {
+	if r.seeker == nil {
+		return 0, ErrSeek
+	}
+
+	size := int64(r.format.BytesPerFrame())
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent, io.SeekEnd:
+		offset, err := r.seeker.Seek(0, whence)
+		if err != nil {
+			return 0, err
+		}
+		// Round down, a partial frame at the end doesn't count.
+		frame += (offset - r.origin) / size
+	default:
+		return 0, errWhence
+	}
+	if frame < 0 {
+		return 0, errOffset
+	}
+
+	offset, err := r.seeker.Seek(r.origin+frame*size, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	return (offset - r.origin) / size, nil
+}
+
+// SetReadDeadline sets the deadline of the io.Reader, a zero value disables the deadline. It
+// returns os.ErrNoDeadline if the io.Reader doesn't support deadlines.
+func (r *reader[T]) SetReadDeadline(t time.Time) error {
+	d, ok := r.r.(readDeadliner)
+	if !ok {
+		return os.ErrNoDeadline
+	}
+	if err := d.SetReadDeadline(t); err != nil {
+		return err
+	}
+	r.deadline = t
+	return nil
+}
+
+// FrameReader can read whole frames of interleaved samples.
+type FrameReader[T Sample] interface {
+	FormatReader[T]
+
+	// ReadFrames reads up to len(samples)/channels frames into samples. It returns the number
+	// of frames read, which is at least one unless an error is returned.
+	//
+	// If the stream ends on a frame boundary, the error is io.EOF. If the stream ends in the
+	// middle of a frame, the error is io.ErrUnexpectedEOF.
+	ReadFrames(Samples[T]) (int, error)
+}
+
+type frameReader[T Sample] struct {
+	r       io.Reader
+	format  Format
+	buf     []byte
+	pending int
+	err     error
+}
+
+// NewFrameReader returns a FrameReader that can read frames in the given format from any
+// io.Reader.
+//
+// Short reads from r are handled by keeping the bytes of partial frames until the next call to
+// ReadFrames, so channels stay aligned. ReadSamples reads whole frames like ReadFullFrames and
+// returns the number of samples read.
+func NewFrameReader[T Sample](r io.Reader, format Format) (FrameReader[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	if !canDecode[T](format) {
+		return nil, fmt.Errorf("audio: can't read %s as %T", format, T(0))
+	}
+	return &frameReader[T]{
+		r:      r,
+		format: format,
+	}, nil
+}
+
+func (r *frameReader[T]) Format() Format {
+	return r.format
+}
+
+func (r *frameReader[T]) ReadFrames(samples Samples[T]) (int, error) {
+	var (
+		channels  = r.format.Channels
+		frameSize = r.format.BytesPerFrame()
+		frames    = len(samples) / channels
+	)
+	if frames == 0 {
+		return 0, io.ErrShortBuffer
+	}
+
+	if size := frames * frameSize; len(r.buf) < size {
+		buf := make([]byte, size)
+		copy(buf, r.buf[:r.pending])
+		r.buf = buf
+	}
+
+	// Read until we have at least one whole frame.
+	for r.pending < frameSize {
+		if r.err != nil {
+			if r.err == io.EOF && r.pending > 0 {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, r.err
+		}
+
+		var n int
+		n, r.err = r.r.Read(r.buf[r.pending : frames*frameSize])
+		r.pending += n
+	}
+
+	// Decode whole frames and carry over the remaining bytes.
+	var (
+		n    = min(r.pending/frameSize, frames)
+		size = n * frameSize
+	)
+	samples[:n*channels].DecodeFormat(r.buf[:size], r.format)
+	r.pending = copy(r.buf, r.buf[size:r.pending])
+	return n, nil
+}
+
+func (r *frameReader[T]) ReadSamples(samples Samples[T]) (int, error) {
+	n, err := ReadFullFrames(r, samples)
+	return n * r.format.Channels, err
+}
+
+// ReadFullFrames reads exactly len(samples)/channels frames from r into samples. It returns the
+// number of frames read.
+//
+// Unlike io.ReadFull, the error is io.EOF if the stream ends on a frame boundary before samples
+// are filled, even if some frames were read. The error is io.ErrUnexpectedEOF only if the stream
+// ends in the middle of a frame.
+func ReadFullFrames[T Sample](r FrameReader[T], samples Samples[T]) (n int, err error) {
+	var (
+		channels = r.Format().Channels
+		frames   = len(samples) / channels
+	)
+	if frames == 0 {
+		return 0, io.ErrShortBuffer
+	}
+
+	for n < frames && err == nil {
+		var m int
+		m, err = r.ReadFrames(samples[n*channels : frames*channels])
+		n += m
+	}
+	if n == frames {
+		err = nil
+	}
+	return
 }
 
 // Writer can write samples.
@@ -34,21 +239,63 @@ type Writer[T Sample] interface {
 }
 
 type writer[T Sample] struct {
-	w     io.Writer
-	order binary.ByteOrder
+	w        io.Writer
+	format   Format
+	buf      []byte
+	deadline time.Time
 }
 
-// NewWriter returns a Writer that can write to any io.Writer.
-func NewWriter[T Sample](w io.Writer, order binary.ByteOrder) Writer[T] {
-	return writer[T]{
-		w:     w,
-		order: order,
+// NewWriter returns a Writer that can write samples in the given format to any io.Writer.
+func NewWriter[T Sample](w io.Writer, format Format) (FormatWriter[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	if !canDecode[T](format) {
+		return nil, fmt.Errorf("audio: can't write %T as %s", T(0), format)
 	}
+	return &writer[T]{
+		w:      w,
+		format: format,
+	}, nil
 }
 
-func (w writer[T]) WriteSamples(samples Samples[T]) (int, error) {
-	i, err := samples.EncodeTo(w.w, w.order)
-	return int(i), err
+func (w *writer[T]) Format() Format {
+	return w.format
+}
+
+// WriteSamples writes samples without allocating, the scratch buffer is reused between calls.
+// If the samples are stored in the byte order of the host, they are written without copying.
+func (w *writer[T]) WriteSamples(samples Samples[T]) (int, error) {
+	if !isHostOrder[T](w.format) {
+		w.buf = scratch.Grow(w.buf, len(samples)*w.format.BytesPerSample())
+	}
+	return samples.encodeToChunked(w.w, w.format, len(samples), w.buf)
+}
+
+// WriteSamplesContext is like WriteSamples, but stops when ctx is done. A blocked write can only
+// be interrupted if the io.Writer supports write deadlines, like files, pipes and connections do.
+func (w *writer[T]) WriteSamplesContext(ctx context.Context, samples Samples[T]) (int, error) {
+	var set func(time.Time) error
+	if d, ok := w.w.(writeDeadliner); ok {
+		set = d.SetWriteDeadline
+	}
+	return withContext(ctx, set, w.deadline, func() (int, error) {
+		return w.WriteSamples(samples)
+	})
+}
+
+// SetWriteDeadline sets the deadline of the io.Writer, a zero value disables the deadline. It
+// returns os.ErrNoDeadline if the io.Writer doesn't support deadlines.
+func (w *writer[T]) SetWriteDeadline(t time.Time) error {
+	d, ok := w.w.(writeDeadliner)
+	if !ok {
+		return os.ErrNoDeadline
+	}
+	if err := d.SetWriteDeadline(t); err != nil {
+		return err
+	}
+	w.deadline = t
+	return nil
 }
 
 // DecodeFrom reads samples from r to s.
@@ -59,40 +306,64 @@ func (s Samples[T]) DecodeFrom(r io.Reader, order binary.ByteOrder) (n int, err
 // DecodeFromChunked reads samples from r to s.
 //
 // If s doesn't contain a multiple of chunkSize samples, an additional smaller chunk will be read
-// to complete to read.
+// to complete to read. Each chunk is read in full, n is the number of samples that were decoded. If
+// r ends with a partial sample, the error is io.ErrUnexpectedEOF, otherwise it is io.EOF.
 func (s Samples[T]) DecodeFromChunked(r io.Reader, order binary.ByteOrder, chunkSize int) (n int, err error) {
+	format := FormatOf[T](0, 1, order)
+	if isHostOrder[T](format) {
+		return s.decodeFromChunked(r, format, chunkSize, nil)
+	}
+
+	buf := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(buf)
+	*buf = scratch.Grow(*buf, min(chunkSize, len(s))*format.BytesPerSample())
+	return s.decodeFromChunked(r, format, chunkSize, *buf)
+}
+
+// decodeFromChunked reads samples from r to s, using buf as scratch buffer for decoding.
+func (s Samples[T]) decodeFromChunked(r io.Reader, format Format, chunkSize int, buf []byte) (n int, err error) {
 	if len(s) == 0 || chunkSize < 1 {
 		return 0, io.ErrShortBuffer
 	}
 
-	var (
-		samples        = len(s)
-		bytesPerSample = s.BitsPerSample() / 8
-		bytesPerChunk  = bytesPerSample * chunkSize
-		buf            = make([]byte, bytesPerChunk)
-	)
-
-	// Read chunks of samples.
-	for ; n < samples; n += chunkSize {
-		if _, err = r.Read(buf); err != nil {
-			return
-		}
-		s[n:n+chunkSize].Decode(buf, order)
+	bytesPerSample := format.BytesPerSample()
+	if isHostOrder[T](format) {
+		// Fast path, read directly into the samples.
+		var m int
+		m, err = io.ReadFull(r, s.Bytes())
+		return m / bytesPerSample, partialSample(err, m, bytesPerSample)
 	}
 
-	// Read remaining bytes if chunkSize doesn't align with the number of samples.
-	if remain := samples % chunkSize; remain > 0 {
-		buf = buf[:bytesPerSample*int(remain)]
-		if _, err = r.Read(buf); err != nil {
-			return
+	// Read chunks of samples, the last chunk may be smaller if chunkSize doesn't align with
+	// the number of samples.
+	samples := len(s)
+	for n < samples {
+		var (
+			size = min(chunkSize, samples-n, len(buf)/bytesPerSample)
+			m    int
+		)
+		m, err = io.ReadFull(r, buf[:bytesPerSample*size])
+
+		// Decode all complete samples, also on short reads.
+		complete := m / bytesPerSample
+		s[n:n+complete].DecodeFormat(buf, format)
+		n += complete
+		if err != nil {
+			return n, partialSample(err, m, bytesPerSample)
 		}
-		s[n:n+remain].Decode(buf, order)
-		n += remain
 	}
 
 	return
 }
 
+// partialSample returns io.EOF instead of io.ErrUnexpectedEOF if n bytes contain whole samples.
+func partialSample(err error, n, bytesPerSample int) error {
+	if err == io.ErrUnexpectedEOF && n%bytesPerSample == 0 {
+		return io.EOF
+	}
+	return err
+}
+
 // Write samples contained in src to w.
 func (s Samples[T]) EncodeTo(w io.Writer, order binary.ByteOrder) (n int, err error) {
 	return s.EncodeToChunked(w, order, len(s))
@@ -100,67 +371,272 @@ func (s Samples[T]) EncodeTo(w io.Writer, order binary.ByteOrder) (n int, err er
 
 // WriteChunked writes samples contained in src to w.
 func (s Samples[T]) EncodeToChunked(w io.Writer, order binary.ByteOrder, chunkSize int) (n int, err error) {
+	format := FormatOf[T](0, 1, order)
+	if isHostOrder[T](format) {
+		return s.encodeToChunked(w, format, chunkSize, nil)
+	}
+
+	buf := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(buf)
+	*buf = scratch.Grow(*buf, min(chunkSize, len(s))*format.BytesPerSample())
+	return s.encodeToChunked(w, format, chunkSize, *buf)
+}
+
+// encodeToChunked writes samples contained in s to w, using buf as scratch buffer for encoding.
+func (s Samples[T]) encodeToChunked(w io.Writer, format Format, chunkSize int, buf []byte) (n int, err error) {
 	if len(s) == 0 || chunkSize < 1 {
 		return
 	}
 
-	var (
-		samples        = len(s)
-		bytesPerSample = s.BitsPerSample() / 8
-		bytesPerChunk  = bytesPerSample * chunkSize
-		buf            = make([]byte, bytesPerChunk)
-	)
-
-	// Write chunks of samples.
-	for ; n < samples; n += chunkSize {
-		s[n:n+chunkSize].Encode(buf, order)
-		if _, err = w.Write(buf); err != nil {
-			return
-		}
+	bytesPerSample := format.BytesPerSample()
+	if isHostOrder[T](format) {
+		// Fast path, write directly from the samples.
+		var m int
+		m, err = w.Write(s.Bytes())
+		return m / bytesPerSample, err
 	}
 
-	// Write remaining bytes if chunkSize doesn't align with the number of samples.
-	if remain := samples % chunkSize; remain > 0 {
-		buf = buf[:bytesPerSample*int(remain)]
-		s[n:].Encode(buf, order)
-		if _, err = w.Write(buf); err != nil {
+	// Write chunks of samples, the last chunk may be smaller if chunkSize doesn't align with
+	// the number of samples.
+	samples := len(s)
+	for n < samples {
+		size := min(chunkSize, samples-n, len(buf)/bytesPerSample)
+		s[n:n+size].EncodeFormat(buf, format)
+		if _, err = w.Write(buf[:bytesPerSample*size]); err != nil {
 			return
 		}
-		n += remain
+		n += size
 	}
 
 	return
 }
 
-/*
+// bufferPool contains scratch buffers for DecodeFromChunked and EncodeToChunked.
+var bufferPool = sync.Pool{
+	New: func() any { return new([]byte) },
+}
+
+// isHostOrder checks if samples of type T are stored in the format in the byte order of the host.
+func isHostOrder[T Sample](format Format) bool {
+	return matches[T](format) && (format.Bits == 8 || isBigEndian(format.ByteOrder) == isBigEndian(binary.NativeEndian))
+}
+
 // BufferReader can read deinterleaved audio samples.
+type BufferReader[T Sample] interface {
+	// ReadBuffer reads up to buffer.Samples() samples into each channel of buffer, and returns
+	// the number of samples read per channel.
+	ReadBuffer(Buffer[T]) (int, error)
+}
+
+type bufferReader[T Sample] struct {
+	r       FrameReader[T]
+	samples Samples[T]
+}
+
+// NewBufferReader returns a BufferReader that can read interleaved samples in the given format
+// from any io.Reader.
+//
+// Each call to ReadBuffer fills the buffer like ReadFullFrames does.
+func NewBufferReader[T Sample](r io.Reader, format Format) (BufferReader[T], error) {
+	fr, err := NewFrameReader[T](r, format)
+	if err != nil {
+		return nil, err
+	}
+	return &bufferReader[T]{r: fr}, nil
+}
+
+func (r *bufferReader[T]) Format() Format {
+	return r.r.Format()
+}
+
+func (r *bufferReader[T]) ReadBuffer(buffer Buffer[T]) (int, error) {
+	channels := r.r.Format().Channels
+	if len(buffer) != channels {
+		return 0, fmt.Errorf("audio: can't read %d channels into a buffer with %d channels", channels, len(buffer))
+	}
+
+	samples := buffer.Samples() * channels
+	r.samples = scratch.Grow(r.samples, samples)
+
+	n, err := ReadFullFrames(r.r, r.samples)
+	Deinterleave(buffer, r.samples[:n*channels], channels)
+	return n, err
+}
+
+type planarReader[T Sample] struct {
+	r      io.ReaderAt
+	format Format
+	frames int64
+	pos    int64
+	buf    []byte
+}
+
+// NewPlanarBufferReader returns a BufferReader that can read planar samples in the given format,
+// where each channel is stored as a contiguous block of frames samples, one channel after the
+// other.
+//
+// Use an io.SectionReader if the samples don't start at offset zero.
+func NewPlanarBufferReader[T Sample](r io.ReaderAt, format Format, frames int64) (BufferReader[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	if !canDecode[T](format) {
+		return nil, fmt.Errorf("audio: can't read %s as %T", format, T(0))
+	}
+	return &planarReader[T]{
+		r:      r,
+		format: format,
+		frames: frames,
+	}, nil
+}
+
+func (r *planarReader[T]) Format() Format {
+	return r.format
+}
+
+func (r *planarReader[T]) ReadBuffer(buffer Buffer[T]) (int, error) {
+	if len(buffer) != r.format.Channels {
+		return 0, fmt.Errorf("audio: can't read %d channels into a buffer with %d channels", r.format.Channels, len(buffer))
+	}
+
+	n := int(min(int64(buffer.Samples()), r.frames-r.pos))
+	if n <= 0 {
+		return 0, io.EOF
+	}
+
+	bytesPerSample := r.format.BytesPerSample()
+	if size := n * bytesPerSample; len(r.buf) < size {
+		r.buf = make([]byte, size)
+	}
+
+	buf := r.buf[:n*bytesPerSample]
+	for c, samples := range buffer {
+		offset := (int64(c)*r.frames + r.pos) * int64(bytesPerSample)
+		if m, err := r.r.ReadAt(buf, offset); m < len(buf) {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		Samples[T](samples[:n]).DecodeFormat(buf, r.format)
+	}
+
+	r.pos += int64(n)
+	return n, nil
+}
+
+// BufferWriter can write deinterleaved audio samples.
 type BufferWriter[T Sample] interface {
+	// WriteBuffer writes buffer.Samples() samples from each channel of buffer, and returns the
+	// number of samples written per channel.
 	WriteBuffer(Buffer[T]) (int, error)
 }
 
 type bufferWriter[T Sample] struct {
-	w     io.Writer
-	order binary.ByteOrder
+	w       FormatWriter[T]
+	samples Samples[T]
 }
 
-func NewBufferWriter[T Sample](w io.Writer, order binary.ByteOrder) BufferWriter[T] {
-	return bufferWriter[T]{
-		w:     w,
-		order: order,
+// NewBufferWriter returns a BufferWriter that can write interleaved samples in the given format
+// to any io.Writer.
+func NewBufferWriter[T Sample](w io.Writer, format Format) (BufferWriter[T], error) {
+	fw, err := NewWriter[T](w, format)
+	if err != nil {
+		return nil, err
 	}
+	return &bufferWriter[T]{w: fw}, nil
+}
+
+func (w *bufferWriter[T]) Format() Format {
+	return w.w.Format()
 }
 
-func (w bufferWriter[T]) WriteBuffer(buffer Buffer[T]) (int, error) {
-	switch len(buffer) {
-	case 0:
+func (w *bufferWriter[T]) WriteBuffer(buffer Buffer[T]) (int, error) {
+	channels := w.w.Format().Channels
+	if len(buffer) != channels {
+		return 0, fmt.Errorf("audio: can't write a buffer with %d channels as %d channels", len(buffer), channels)
+	}
+
+	frames := buffer.Samples()
+	if frames == 0 {
 		// Fast path, nothing to do.
 		return 0, nil
+	}
 
-	case 1:
-		// Fast path, single buffer.
-		return Samples[T](buffer[0]).EncodeTo(w.w, w.order)
+	samples := frames * channels
+	w.samples = scratch.Grow(w.samples, samples)
+	interleaved := w.samples
+	for c, channel := range buffer {
+		for i, v := range channel[:frames] {
+			interleaved[i*channels+c] = v
+		}
+	}
 
-	default:
+	n, err := w.w.WriteSamples(interleaved)
+	return n / channels, err
+}
+
+type planarWriter[T Sample] struct {
+	w      io.WriterAt
+	format Format
+	frames int64
+	pos    int64
+	buf    []byte
+}
+
+// NewPlanarBufferWriter returns a BufferWriter that can write planar samples in the given format,
+// where each channel is stored as a contiguous block of frames samples, one channel after the
+// other.
+//
+// Writing more than frames samples per channel returns io.ErrShortWrite.
+func NewPlanarBufferWriter[T Sample](w io.WriterAt, format Format, frames int64) (BufferWriter[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	if !canDecode[T](format) {
+		return nil, fmt.Errorf("audio: can't write %T as %s", T(0), format)
+	}
+	return &planarWriter[T]{
+		w:      w,
+		format: format,
+		frames: frames,
+	}, nil
+}
+
+func (w *planarWriter[T]) Format() Format {
+	return w.format
+}
+
+func (w *planarWriter[T]) WriteBuffer(buffer Buffer[T]) (int, error) {
+	if len(buffer) != w.format.Channels {
+		return 0, fmt.Errorf("audio: can't write a buffer with %d channels as %d channels", len(buffer), w.format.Channels)
+	}
+
+	var (
+		want = buffer.Samples()
+		n    = int(min(int64(want), w.frames-w.pos))
+	)
+	if n <= 0 && want > 0 {
+		return 0, io.ErrShortWrite
+	}
+
+	bytesPerSample := w.format.BytesPerSample()
+	if size := n * bytesPerSample; len(w.buf) < size {
+		w.buf = make([]byte, size)
+	}
+
+	buf := w.buf[:n*bytesPerSample]
+	for c, samples := range buffer {
+		Samples[T](samples[:n]).EncodeFormat(buf, w.format)
+		offset := (int64(c)*w.frames + w.pos) * int64(bytesPerSample)
+		if _, err := w.w.WriteAt(buf, offset); err != nil {
+			return 0, err
+		}
+	}
+
+	w.pos += int64(n)
+	if n < want {
+		return n, io.ErrShortWrite
 	}
+	return n, nil
 }
-*/
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"io"
 	"testing"
+	"testing/iotest"
 
 	"github.com/BeatGlow/audio"
 )
@@ -87,3 +88,342 @@ func TestDecodeFrom(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeFromShortReads(t *testing.T) {
+	var (
+		test   = []byte{0x00, 0x01, 0x00, 0x02, 0x00, 0x03, 0x00, 0x04, 0x00}
+		want   = audio.Samples[int16]{1, 2, 3, 4}
+		buffer = make(audio.Samples[int16], 8)
+	)
+
+	n, err := buffer.DecodeFrom(iotest.OneByteReader(bytes.NewBuffer(test)), binary.BigEndian)
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected error %q, got %q", io.ErrUnexpectedEOF, err)
+	}
+	if n != len(want) {
+		t.Fatalf("expected %d samples, got %d", len(want), n)
+	}
+	for i, v := range want {
+		if v != buffer[i] {
+			t.Errorf("expected sample %d to be %d, got %d", i, v, buffer[i])
+		}
+	}
+}
+
+func TestFrameReader(t *testing.T) {
+	var (
+		format = audio.FormatOf[int16](8000, 2, binary.LittleEndian)
+		test   = make([]byte, 100*format.BytesPerFrame())
+	)
+	for i := 0; i < len(test)/2; i++ {
+		// Left channel counts up, right channel counts down.
+		v := int16(i / 2)
+		if i%2 == 1 {
+			v = -v
+		}
+		binary.LittleEndian.PutUint16(test[i*2:], uint16(v))
+	}
+
+	for _, wrap := range []struct {
+		Name string
+		Wrap func(io.Reader) io.Reader
+	}{
+		{"full", func(r io.Reader) io.Reader { return r }},
+		{"one byte", iotest.OneByteReader},
+		{"half", iotest.HalfReader},
+		{"data err", iotest.DataErrReader},
+	} {
+		t.Run(wrap.Name, func(it *testing.T) {
+			r, err := audio.NewFrameReader[int16](wrap.Wrap(bytes.NewReader(test)), format)
+			if err != nil {
+				it.Fatal(err)
+			}
+
+			var (
+				buffer = make(audio.Samples[int16], 14) // 7 frames
+				frames int
+			)
+			for {
+				n, err := r.ReadFrames(buffer)
+				for i := 0; i < n; i++ {
+					if l, r := buffer[i*2], buffer[i*2+1]; l != int16(frames) || r != -int16(frames) {
+						it.Fatalf("expected frame %d to be %d,%d, got %d,%d", frames, frames, -frames, l, r)
+					}
+					frames++
+				}
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					it.Fatal(err)
+				}
+			}
+			if frames != 100 {
+				it.Fatalf("expected 100 frames, got %d", frames)
+			}
+		})
+	}
+}
+
+func TestFrameReaderPartialFrame(t *testing.T) {
+	format := audio.FormatOf[int16](8000, 2, binary.LittleEndian)
+	r, err := audio.NewFrameReader[int16](bytes.NewReader([]byte{1, 0, 2, 0, 3, 0}), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := make(audio.Samples[int16], 4)
+	n, err := audio.ReadFullFrames(r, buffer)
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected error %q, got %q", io.ErrUnexpectedEOF, err)
+	}
+	if n != 1 || buffer[0] != 1 || buffer[1] != 2 {
+		t.Fatalf("expected 1 frame of 1,2, got %d frames: %d", n, buffer[:n*2])
+	}
+}
+
+func TestFrameReaderReadSamples(t *testing.T) {
+	format := audio.FormatOf[uint8](8000, 3, nil)
+	r, err := audio.NewFrameReader[uint8](iotest.OneByteReader(bytes.NewReader([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9})), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A buffer of 8 samples only fits 2 frames.
+	buffer := make(audio.Samples[uint8], 8)
+	if n, err := r.ReadSamples(buffer); err != nil {
+		t.Fatal(err)
+	} else if n != 6 {
+		t.Fatalf("expected 6 samples, got %d", n)
+	}
+	if n, err := r.ReadSamples(buffer); err != io.EOF {
+		t.Fatalf("expected error %q, got %q", io.EOF, err)
+	} else if n != 3 || buffer[0] != 7 {
+		t.Fatalf("expected 3 samples starting at 7, got %d: %d", n, buffer[:n])
+	}
+}
+
+func TestBufferReaderWriter(t *testing.T) {
+	var (
+		format = audio.FormatOf[int16](8000, 2, binary.BigEndian)
+		want   = audio.Buffer[int16]{
+			{1, 2, 3, 4, 5},
+			{-1, -2, -3, -4, -5},
+		}
+		encoded bytes.Buffer
+	)
+
+	w, err := audio.NewBufferWriter[int16](&encoded, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := w.WriteBuffer(want); err != nil {
+		t.Fatal(err)
+	} else if n != 5 {
+		t.Fatalf("expected 5 samples written, got %d", n)
+	}
+	if v := encoded.Bytes()[:4]; !bytes.Equal(v, []byte{0x00, 0x01, 0xff, 0xff}) {
+		t.Fatalf("expected the first frame to be interleaved, got %#02v", v)
+	}
+
+	r, err := audio.NewBufferReader[int16](iotest.HalfReader(&encoded), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		test   = audio.Buffer[int16]{make([]int16, 3), make([]int16, 3)}
+		offset int
+	)
+	for _, frames := range []int{3, 2} {
+		n, err := r.ReadBuffer(test)
+		if n != frames {
+			t.Fatalf("expected %d samples, got %d (%v)", frames, n, err)
+		}
+		for c := range test {
+			for i, v := range test[c][:n] {
+				if v != want[c][offset+i] {
+					t.Errorf("expected sample %d in channel %d to be %d, got %d", offset+i, c, want[c][offset+i], v)
+				}
+			}
+		}
+		offset += n
+	}
+	if _, err = r.ReadBuffer(test); err != io.EOF {
+		t.Fatalf("expected error %q, got %q", io.EOF, err)
+	}
+}
+
+type testWriterAt []byte
+
+func (w testWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	return copy(w[off:], p), nil
+}
+
+func TestPlanarBufferReaderWriter(t *testing.T) {
+	var (
+		format  = audio.Format{SampleRate: 8000, Channels: 3, Encoding: audio.Signed, Bits: 24, ByteOrder: binary.LittleEndian}
+		want    = audio.Buffer[int32]{{1 << 8, 2 << 8, 3 << 8}, {4 << 8, 5 << 8, 6 << 8}, {7 << 8, 8 << 8, 9 << 8}}
+		encoded = make(testWriterAt, 3*3*3)
+	)
+
+	w, err := audio.NewPlanarBufferWriter[int32](encoded, format, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		frame := audio.Buffer[int32]{want[0][i : i+1], want[1][i : i+1], want[2][i : i+1]}
+		if _, err = w.WriteBuffer(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = w.WriteBuffer(want); err != io.ErrShortWrite {
+		t.Fatalf("expected error %q, got %q", io.ErrShortWrite, err)
+	}
+	if v := []byte(encoded[9:15]); !bytes.Equal(v, []byte{4, 0, 0, 5, 0, 0}) {
+		t.Fatalf("expected the second channel to be contiguous, got %#02v", v)
+	}
+
+	r, err := audio.NewPlanarBufferReader[int32](bytes.NewReader(encoded), format, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := audio.Buffer[int32]{make([]int32, 4), make([]int32, 4), make([]int32, 4)}
+	if n, err := r.ReadBuffer(test); err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Fatalf("expected 3 samples, got %d", n)
+	}
+	for c := range want {
+		for i, v := range want[c] {
+			if test[c][i] != v {
+				t.Errorf("expected sample %d in channel %d to be %d, got %d", i, c, v, test[c][i])
+			}
+		}
+	}
+	if _, err = r.ReadBuffer(test); err != io.EOF {
+		t.Fatalf("expected error %q, got %q", io.EOF, err)
+	}
+}
+
+// testZero is an endless stream of zero bytes.
+type testZero struct{}
+
+func (testZero) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestReaderWriterAllocs(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Format audio.Format
+	}{
+		{"native", audio.FormatOf[int16](44100, 2, binary.NativeEndian)},
+		{"big endian", audio.FormatOf[int16](44100, 2, binary.BigEndian)},
+		{"little endian", audio.FormatOf[int16](44100, 2, binary.LittleEndian)},
+		{"packed", audio.Format{SampleRate: 44100, Channels: 2, Encoding: audio.Signed, Bits: 24, ByteOrder: binary.LittleEndian}},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			r, err := audio.NewReader[int16](testZero{}, test.Format)
+			if err != nil {
+				it.Fatal(err)
+			}
+			w, err := audio.NewWriter[int16](io.Discard, test.Format)
+			if err != nil {
+				it.Fatal(err)
+			}
+
+			samples := make(audio.Samples[int16], 1024)
+			if n := testing.AllocsPerRun(100, func() {
+				if _, err := r.ReadSamples(samples); err != nil {
+					it.Fatal(err)
+				}
+			}); n != 0 {
+				it.Errorf("expected ReadSamples to not allocate, got %g allocations", n)
+			}
+			if n := testing.AllocsPerRun(100, func() {
+				if _, err := w.WriteSamples(samples); err != nil {
+					it.Fatal(err)
+				}
+			}); n != 0 {
+				it.Errorf("expected WriteSamples to not allocate, got %g allocations", n)
+			}
+		})
+	}
+}
+
+func TestSamplesBytes(t *testing.T) {
+	s := audio.Samples[uint16]{0x0102, 0x0304}
+	if v, want := s.Bytes(), binary.NativeEndian.AppendUint16(binary.NativeEndian.AppendUint16(nil, 0x0102), 0x0304); !bytes.Equal(v, want) {
+		t.Fatalf("expected %#02v, got %#02v", want, v)
+	}
+	s.Bytes()[0] = 0xff
+	if s[0] == 0x0102 {
+		t.Error("expected the bytes to share memory with the samples")
+	}
+	if v := (audio.Samples[uint16]{}).Bytes(); len(v) != 0 {
+		t.Errorf("expected no bytes, got %d", len(v))
+	}
+}
+
+func BenchmarkReadSamples(b *testing.B) {
+	b.Run("native", func(b *testing.B) {
+		benchmarkReadSamples[float32](b, audio.FormatOf[float32](48000, 2, binary.NativeEndian))
+	})
+	b.Run("swapped", func(b *testing.B) {
+		benchmarkReadSamples[float32](b, audio.FormatOf[float32](48000, 2, testSwapped()))
+	})
+	b.Run("s24le", func(b *testing.B) {
+		benchmarkReadSamples[int32](b, audio.Format{SampleRate: 48000, Channels: 2, Encoding: audio.Signed, Bits: 24, ByteOrder: binary.LittleEndian})
+	})
+}
+
+func benchmarkReadSamples[T audio.Sample](b *testing.B, format audio.Format) {
+	r, err := audio.NewReader[T](testZero{}, format)
+	if err != nil {
+		b.Fatal(err)
+	}
+	samples := make(audio.Samples[T], 4096)
+	b.SetBytes(int64(len(samples) * format.BytesPerSample()))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err = r.ReadSamples(samples); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteSamples(b *testing.B) {
+	b.Run("native", func(b *testing.B) {
+		benchmarkWriteSamples[float32](b, audio.FormatOf[float32](48000, 2, binary.NativeEndian))
+	})
+	b.Run("swapped", func(b *testing.B) {
+		benchmarkWriteSamples[float32](b, audio.FormatOf[float32](48000, 2, testSwapped()))
+	})
+	b.Run("s24le", func(b *testing.B) {
+		benchmarkWriteSamples[int32](b, audio.Format{SampleRate: 48000, Channels: 2, Encoding: audio.Signed, Bits: 24, ByteOrder: binary.LittleEndian})
+	})
+}
+
+func benchmarkWriteSamples[T audio.Sample](b *testing.B, format audio.Format) {
+	w, err := audio.NewWriter[T](io.Discard, format)
+	if err != nil {
+		b.Fatal(err)
+	}
+	samples := make(audio.Samples[T], 4096)
+	b.SetBytes(int64(len(samples) * format.BytesPerSample()))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err = w.WriteSamples(samples); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// testSwapped returns the byte order that is not the host byte order.
+func testSwapped() binary.ByteOrder {
+	if binary.NativeEndian.Uint16([]byte{0, 1}) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
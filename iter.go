@@ -0,0 +1,129 @@
+package audio
+
+import (
+	"errors"
+	"io"
+	"iter"
+)
+
+var ErrHop = errors.New("audio: hop must be between 1 and the window size")
+
+// Blocks returns an iterator over blocks of size samples read from r. The last block is shorter
+// if r ends in the middle of a block. Use a multiple of the number of channels as size to get
+// blocks of whole frames.
+//
+// The block is reused, it is only valid until the next iteration. Iteration stops at io.EOF, other
+// errors are yielded with a nil block before iteration stops.
+func Blocks[T Sample](r Reader[T], size int) iter.Seq2[Samples[T], error] {
+	return func(yield func(Samples[T], error) bool) {
+		if size < 1 {
+			yield(nil, io.ErrShortBuffer)
+			return
+		}
+		block := make(Samples[T], size)
+		for {
+			n, err := readBlock(r, block)
+			if n > 0 && !yield(block[:n], nil) {
+				return
+			}
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
+
+// Windows returns an iterator over overlapping windows of size samples read from r, where each
+// window starts hop samples after the previous window. A hop of size/2 gives windows with 50%
+// overlap, which is common for spectral analysis. The last window is padded with zeros if r ends
+// before the window is complete. Use multiples of the number of channels as size and hop to get
+// windows of whole frames.
+//
+// The window is reused, it is only valid until the next iteration and must not be modified.
+// Iteration stops at io.EOF, other errors are yielded with a nil window before iteration stops.
+func Windows[T Sample](r Reader[T], size, hop int) iter.Seq2[Samples[T], error] {
+	return func(yield func(Samples[T], error) bool) {
+		if hop < 1 || hop > size {
+			yield(nil, ErrHop)
+			return
+		}
+		var (
+			window = make(Samples[T], size)
+			filled int // number of samples in window that were read
+			fresh  int // number of samples in window that weren't yielded before
+		)
+		for {
+			n, err := readBlock(r, window[filled:])
+			filled += n
+			fresh += n
+			if err != nil && err != io.EOF {
+				yield(nil, err)
+				return
+			}
+			if err == io.EOF {
+				if fresh > 0 {
+					clear(window[filled:])
+					yield(window, nil)
+				}
+				return
+			}
+			if !yield(window, nil) {
+				return
+			}
+
+			// Keep the overlap for the next window.
+			filled = copy(window, window[hop:filled])
+			fresh = 0
+		}
+	}
+}
+
+// readBlock reads from r until block is full or an error occurs.
+func readBlock[T Sample](r Reader[T], block Samples[T]) (int, error) {
+	var n int
+	for n < len(block) {
+		m, err := r.ReadSamples(block[n:])
+		n += m
+		if err != nil {
+			return n, err
+		}
+		if m == 0 {
+			return n, io.ErrNoProgress
+		}
+	}
+	return n, nil
+}
+
+// Frames returns an iterator over the index and samples of each frame. The frame is a slice of
+// s, so modifying it modifies s.
+func (s Samples[T]) Frames(channels int) iter.Seq2[int, Samples[T]] {
+	return func(yield func(int, Samples[T]) bool) {
+		if channels < 1 {
+			return
+		}
+		for i := 0; i+channels <= len(s); i += channels {
+			if !yield(i/channels, s[i:i+channels:i+channels]) {
+				return
+			}
+		}
+	}
+}
+
+// Frames returns an iterator over the index and samples of each frame, with one sample per
+// channel. The frame is reused, it is only valid until the next iteration.
+func (b Buffer[T]) Frames() iter.Seq2[int, Samples[T]] {
+	return func(yield func(int, Samples[T]) bool) {
+		frame := make(Samples[T], len(b))
+		for i := range b.Samples() {
+			for c, samples := range b {
+				frame[c] = samples[i]
+			}
+			if !yield(i, frame) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package audio_test
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/dsp"
+	"github.com/BeatGlow/audio/dsp/window"
+	"github.com/BeatGlow/audio/filter"
+	"github.com/BeatGlow/audio/generator"
+)
+
+// testCounter is a reader of n samples counting up from 1, returning at most max samples per read.
+type testCounter struct {
+	n, max, next int
+	err          error
+}
+
+func (r *testCounter) ReadSamples(samples audio.Samples[int]) (int, error) {
+	if r.next >= r.n {
+		if r.err != nil {
+			return 0, r.err
+		}
+		return 0, io.EOF
+	}
+	samples = samples[:min(len(samples), r.max, r.n-r.next)]
+	for i := range samples {
+		r.next++
+		samples[i] = r.next
+	}
+	return len(samples), nil
+}
+
+func TestBlocks(t *testing.T) {
+	var got []audio.Samples[int]
+	for block, err := range audio.Blocks[int](&testCounter{n: 10, max: 3}, 4) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, slices.Clone(block))
+	}
+	want := []audio.Samples[int]{{1, 2, 3, 4}, {5, 6, 7, 8}, {9, 10}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// Errors other than io.EOF are yielded.
+	fail := errors.New("fail")
+	var errs []error
+	for _, err := range audio.Blocks[int](&testCounter{n: 4, max: 4, err: fail}, 4) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 1 || errs[0] != fail {
+		t.Errorf("expected error %v, got %v", fail, errs)
+	}
+
+	// Breaking out of the loop stops reading.
+	r := &testCounter{n: 100, max: 100}
+	for range audio.Blocks[int](r, 4) {
+		break
+	}
+	if r.next != 4 {
+		t.Errorf("expected 4 samples read, got %d", r.next)
+	}
+}
+
+func TestWindows(t *testing.T) {
+	var got []audio.Samples[int]
+	for window, err := range audio.Windows[int](&testCounter{n: 10, max: 3}, 4, 2) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, slices.Clone(window))
+	}
+	want := []audio.Samples[int]{{1, 2, 3, 4}, {3, 4, 5, 6}, {5, 6, 7, 8}, {7, 8, 9, 10}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// The last window is padded.
+	got = got[:0]
+	for window, err := range audio.Windows[int](&testCounter{n: 7, max: 7}, 4, 3) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, slices.Clone(window))
+	}
+	want = []audio.Samples[int]{{1, 2, 3, 4}, {4, 5, 6, 7}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	got = got[:0]
+	for window, err := range audio.Windows[int](&testCounter{n: 9, max: 7}, 4, 3) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, slices.Clone(window))
+	}
+	want = []audio.Samples[int]{{1, 2, 3, 4}, {4, 5, 6, 7}, {7, 8, 9, 0}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	for _, err := range audio.Windows[int](&testCounter{}, 4, 5) {
+		if err != audio.ErrHop {
+			t.Errorf("expected ErrHop, got %v", err)
+		}
+	}
+}
+
+func TestFrames(t *testing.T) {
+	samples := audio.Samples[int16]{1, 2, 3, 4, 5, 6, 7}
+	var got []audio.Samples[int16]
+	for i, frame := range samples.Frames(2) {
+		if len(got) != i {
+			t.Errorf("expected frame %d, got %d", len(got), i)
+		}
+		got = append(got, frame)
+	}
+	if want := "[[1 2] [3 4] [5 6]]"; fmt.Sprint(got) != want {
+		t.Errorf("expected %s, got %v", want, got)
+	}
+
+	buffer := audio.Buffer[int16]{{1, 3, 5}, {2, 4, 6, 8}}
+	got = got[:0]
+	for _, frame := range buffer.Frames() {
+		got = append(got, slices.Clone(frame))
+	}
+	if want := "[[1 2] [3 4] [5 6]]"; fmt.Sprint(got) != want {
+		t.Errorf("expected %s, got %v", want, got)
+	}
+}
+
+func ExampleWindows() {
+	format := audio.FormatOf[float64](8000, 1, binary.NativeEndian)
+	tone, _ := generator.Sine[float64](format, generator.Tone{Frequency: 1000, Amplitude: 1})
+	r, _ := filter.NewFadeOut[float64](tone, format, 32*time.Millisecond, filter.LinearCurve)
+
+	// Analyse windows of 64 samples with 50% overlap.
+	var (
+		c      = dsp.NewFrequencyPowerCalculator[float64](format, window.Hann(64))
+		powers []dsp.FrequencyPower
+	)
+	for samples, err := range audio.Windows[float64](r, 64, 32) {
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		powers = c.Apply(powers, samples)
+		peak := slices.MaxFunc(powers, func(a, b dsp.FrequencyPower) int {
+			return cmp.Compare(a.Magnitude, b.Magnitude)
+		})
+		fmt.Printf("%d Hz, %.1f\n", peak.Frequency, peak.Magnitude)
+	}
+	// Output:
+	// 1000 Hz, 13.8
+	// 1000 Hz, 11.8
+	// 1000 Hz, 9.9
+	// 1000 Hz, 7.9
+	// 1000 Hz, 5.9
+	// 1000 Hz, 4.0
+	// 1000 Hz, 2.0
+}
@@ -0,0 +1,151 @@
+package audio
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Channel is a speaker position. The values are the bit positions of the speakers in the
+// WAVE_FORMAT_EXTENSIBLE channel mask.
+type Channel uint8
+
+const (
+	FrontLeft Channel = iota
+	FrontRight
+	FrontCenter
+	LowFrequency
+	BackLeft
+	BackRight
+	FrontLeftOfCenter
+	FrontRightOfCenter
+	BackCenter
+	SideLeft
+	SideRight
+)
+
+var channelNames = [...]string{
+	FrontLeft:          "FL",
+	FrontRight:         "FR",
+	FrontCenter:        "FC",
+	LowFrequency:       "LFE",
+	BackLeft:           "BL",
+	BackRight:          "BR",
+	FrontLeftOfCenter:  "FLC",
+	FrontRightOfCenter: "FRC",
+	BackCenter:         "BC",
+	SideLeft:           "SL",
+	SideRight:          "SR",
+}
+
+func (c Channel) String() string {
+	if int(c) < len(channelNames) {
+		return channelNames[c]
+	}
+	return fmt.Sprintf("unknown channel %d", c)
+}
+
+// Layout is the order of the channels in a frame.
+type Layout []Channel
+
+// Common channel layouts. Layouts without a suffix use the WAV channel order, which is the order
+// of the channel mask bits; the ALSA variants use the order of the ALSA default channel maps.
+var (
+	Mono           = Layout{FrontCenter}
+	Stereo         = Layout{FrontLeft, FrontRight}
+	Stereo21       = Layout{FrontLeft, FrontRight, LowFrequency}
+	Quad           = Layout{FrontLeft, FrontRight, BackLeft, BackRight}
+	Surround51     = Layout{FrontLeft, FrontRight, FrontCenter, LowFrequency, BackLeft, BackRight}
+	Surround51ALSA = Layout{FrontLeft, FrontRight, BackLeft, BackRight, FrontCenter, LowFrequency}
+	Surround71     = Layout{FrontLeft, FrontRight, FrontCenter, LowFrequency, BackLeft, BackRight, SideLeft, SideRight}
+	Surround71ALSA = Layout{FrontLeft, FrontRight, BackLeft, BackRight, FrontCenter, LowFrequency, SideLeft, SideRight}
+)
+
+var layoutNames = []struct {
+	layout Layout
+	name   string
+}{
+	{Mono, "mono"},
+	{Stereo, "stereo"},
+	{Stereo21, "2.1"},
+	{Quad, "quad"},
+	{Surround51, "5.1"},
+	{Surround51ALSA, "5.1 (ALSA)"},
+	{Surround71, "7.1"},
+	{Surround71ALSA, "7.1 (ALSA)"},
+}
+
+// DefaultLayout returns the WAV layout for the number of channels, or nil if there is none.
+func DefaultLayout(channels int) Layout {
+	switch channels {
+	case 1:
+		return Mono
+	case 2:
+		return Stereo
+	case 3:
+		return Stereo21
+	case 4:
+		return Quad
+	case 6:
+		return Surround51
+	case 8:
+		return Surround71
+	default:
+		return nil
+	}
+}
+
+// LayoutOfMask returns the layout of a WAVE_FORMAT_EXTENSIBLE channel mask.
+func LayoutOfMask(mask uint32) Layout {
+	var l Layout
+	for c := Channel(0); int(c) < len(channelNames); c++ {
+		if mask&(1<<c) != 0 {
+			l = append(l, c)
+		}
+	}
+	return l
+}
+
+// Mask returns the WAVE_FORMAT_EXTENSIBLE channel mask of the layout.
+func (l Layout) Mask() uint32 {
+	var mask uint32
+	for _, c := range l {
+		mask |= 1 << c
+	}
+	return mask
+}
+
+// Index returns the position of c in the frame, or -1 if the layout has no such channel.
+func (l Layout) Index(c Channel) int {
+	for i, v := range l {
+		if v == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// Equal checks if both layouts have the same channels in the same order.
+func (l Layout) Equal(other Layout) bool {
+	if len(l) != len(other) {
+		return false
+	}
+	for i, c := range l {
+		if other[i] != c {
+			return false
+		}
+	}
+	return true
+}
+
+func (l Layout) String() string {
+	for _, known := range layoutNames {
+		if l.Equal(known.layout) {
+			return known.name
+		}
+	}
+	names := make([]string, len(l))
+	for i, c := range l {
+		names[i] = c.String()
+	}
+	return strings.Join(names, "+")
+}
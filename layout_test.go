@@ -0,0 +1,45 @@
+package audio_test
+
+import (
+	"testing"
+
+	"github.com/BeatGlow/audio"
+)
+
+func TestLayout(t *testing.T) {
+	testCases := []struct {
+		Layout audio.Layout
+		Mask   uint32
+		Name   string
+	}{
+		{audio.Mono, 0x4, "mono"},
+		{audio.Stereo, 0x3, "stereo"},
+		{audio.Stereo21, 0xb, "2.1"},
+		{audio.Quad, 0x33, "quad"},
+		{audio.Surround51, 0x3f, "5.1"},
+		{audio.Surround71, 0x63f, "7.1"},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			if v := test.Layout.String(); v != test.Name {
+				it.Errorf("expected name %q, got %q", test.Name, v)
+			}
+			if v := test.Layout.Mask(); v != test.Mask {
+				it.Errorf("expected mask %#x, got %#x", test.Mask, v)
+			}
+			if v := audio.LayoutOfMask(test.Mask); !v.Equal(test.Layout) {
+				it.Errorf("expected mask %#x to have layout %s, got %s", test.Mask, test.Layout, v)
+			}
+			if v := audio.DefaultLayout(len(test.Layout)); !v.Equal(test.Layout) {
+				it.Errorf("expected default layout %s, got %s", test.Layout, v)
+			}
+		})
+	}
+
+	if v := audio.Surround51ALSA.String(); v != "5.1 (ALSA)" {
+		t.Errorf("unexpected name %q", v)
+	}
+	if v := (audio.Layout{audio.FrontCenter, audio.BackCenter}).String(); v != "FC+BC" {
+		t.Errorf("unexpected name %q", v)
+	}
+}
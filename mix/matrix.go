@@ -0,0 +1,219 @@
+// Package mix contains channel mixing stages.
+package mix
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/scratch"
+)
+
+var (
+	ErrLayout = errors.New("mix: need a channel layout with at least one channel")
+	ErrMatrix = errors.New("mix: matrix doesn't match the number of channels")
+)
+
+// Matrix contains the gain of every input channel (column) in every output channel (row).
+type Matrix [][]float64
+
+// minus3dB is the ITU-R BS.775 gain for folding a channel into two other channels.
+const minus3dB = math.Sqrt2 / 2
+
+type route struct {
+	to   audio.Channel
+	gain float64
+}
+
+// routes lists where a channel goes if the output layout doesn't have it. The first option with
+// all channels in the output layout is used, or else the last option is routed further.
+var routes = map[audio.Channel][][]route{
+	audio.FrontLeft:          {{{audio.FrontCenter, 0.5}}},
+	audio.FrontRight:         {{{audio.FrontCenter, 0.5}}},
+	audio.FrontCenter:        {{{audio.FrontLeft, minus3dB}, {audio.FrontRight, minus3dB}}},
+	audio.FrontLeftOfCenter:  {{{audio.FrontLeft, 1}}},
+	audio.FrontRightOfCenter: {{{audio.FrontRight, 1}}},
+	audio.BackLeft:           {{{audio.SideLeft, 1}}, {{audio.FrontLeft, minus3dB}}},
+	audio.BackRight:          {{{audio.SideRight, 1}}, {{audio.FrontRight, minus3dB}}},
+	audio.SideLeft:           {{{audio.BackLeft, 1}}, {{audio.FrontLeft, minus3dB}}},
+	audio.SideRight:          {{{audio.BackRight, 1}}, {{audio.FrontRight, minus3dB}}},
+	audio.BackCenter: {
+		{{audio.BackLeft, minus3dB}, {audio.BackRight, minus3dB}},
+		{{audio.SideLeft, minus3dB}, {audio.SideRight, minus3dB}},
+		{{audio.FrontLeft, minus3dB}, {audio.FrontRight, minus3dB}},
+	},
+}
+
+// NewMatrix returns the matrix that mixes channels in the from layout to the to layout.
+//
+// Channels that are in both layouts are copied, which also reorders channels between layouts.
+// Missing channels are folded into the remaining channels with the ITU-R BS.775 coefficients,
+// so 5.1 is mixed to stereo as L = FL + 0.707 FC + 0.707 BL, and mono is mixed to stereo as
+// L = R = 0.707 FC. The low frequency channel is dropped if the output doesn't have one.
+func NewMatrix(from, to audio.Layout) (Matrix, error) {
+	if len(from) == 0 || len(to) == 0 {
+		return nil, ErrLayout
+	}
+
+	m := make(Matrix, len(to))
+	for i := range m {
+		m[i] = make([]float64, len(from))
+	}
+	for i, c := range from {
+		m.route(to, i, c, 1, len(routes))
+	}
+	return m, nil
+}
+
+func (m Matrix) route(to audio.Layout, input int, c audio.Channel, gain float64, depth int) {
+	if output := to.Index(c); output >= 0 {
+		m[output][input] += gain
+		return
+	}
+
+	options := routes[c]
+	if len(options) == 0 || depth == 0 {
+		return
+	}
+	option := options[len(options)-1]
+	for _, o := range options {
+		if present(to, o) {
+			option = o
+			break
+		}
+	}
+	for _, r := range option {
+		m.route(to, input, r.to, gain*r.gain, depth-1)
+	}
+}
+
+func present(l audio.Layout, option []route) bool {
+	for _, r := range option {
+		if l.Index(r.to) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Inputs is the number of input channels.
+func (m Matrix) Inputs() int {
+	if len(m) == 0 {
+		return 0
+	}
+	return len(m[0])
+}
+
+// Outputs is the number of output channels.
+func (m Matrix) Outputs() int {
+	return len(m)
+}
+
+// Normalize scales all gains down so no output channel can clip, if required.
+func (m Matrix) Normalize() Matrix {
+	var max float64
+	for _, row := range m {
+		var sum float64
+		for _, gain := range row {
+			sum += math.Abs(gain)
+		}
+		max = math.Max(max, sum)
+	}
+	if max <= 1 {
+		return m
+	}
+	for _, row := range m {
+		for i := range row {
+			row[i] /= max
+		}
+	}
+	return m
+}
+
+// Apply mixes the interleaved frames in src to dst, which must fit the same number of frames.
+func (m Matrix) Apply(dst, src audio.Samples[float64]) {
+	inputs, outputs := m.Inputs(), m.Outputs()
+	for frame := 0; frame < len(src)/inputs; frame++ {
+		in, out := src[frame*inputs:], dst[frame*outputs:]
+		for o, row := range m {
+			var v float64
+			for i, gain := range row {
+				v += gain * in[i]
+			}
+			out[o] = v
+		}
+	}
+}
+
+func (m Matrix) valid() bool {
+	if len(m) == 0 || len(m[0]) == 0 {
+		return false
+	}
+	for _, row := range m {
+		if len(row) != len(m[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Remix mixes the channels of a Reader with a Matrix.
+type Remix[T audio.Sample] struct {
+	reader audio.Reader[T]
+	matrix Matrix
+	format audio.Format
+	in     audio.Samples[T]
+	src    audio.Samples[float64]
+	dst    audio.Samples[float64]
+}
+
+// NewRemix mixes the channels of samples in the given format read from r with the matrix.
+//
+// Integer samples are clipped if the mixed value is out of range, see Matrix.Normalize.
+func NewRemix[T audio.Sample](r audio.Reader[T], format audio.Format, matrix Matrix) (audio.FormatReader[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	if !matrix.valid() || matrix.Inputs() != format.Channels {
+		return nil, ErrMatrix
+	}
+
+	output := format
+	output.Channels = matrix.Outputs()
+	return &Remix[T]{
+		reader: r,
+		matrix: matrix,
+		format: output,
+	}, nil
+}
+
+// Format of the mixed samples.
+func (r *Remix[T]) Format() audio.Format {
+	return r.format
+}
+
+func (r *Remix[T]) String() string {
+	return fmt.Sprintf("remix %d → %d channels", r.matrix.Inputs(), r.matrix.Outputs())
+}
+
+// ReadSamples reads as many whole frames as fit in samples.
+func (r *Remix[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	inputs, outputs := r.matrix.Inputs(), r.matrix.Outputs()
+	frames := len(samples) / outputs
+	if frames == 0 {
+		return 0, io.ErrShortBuffer
+	}
+
+	r.in = scratch.Grow(r.in, frames*inputs)
+	n, err := r.reader.ReadSamples(r.in)
+	frames = n / inputs
+
+	r.src = scratch.Grow(r.src, frames*inputs)
+	r.dst = scratch.Grow(r.dst, frames*outputs)
+	audio.Convert(r.src, r.in[:frames*inputs])
+	r.matrix.Apply(r.dst, r.src)
+	audio.Convert(samples, r.dst)
+	return frames * outputs, err
+}
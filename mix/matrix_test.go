@@ -0,0 +1,128 @@
+package mix
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+)
+
+func TestNewMatrix(t *testing.T) {
+	const g = minus3dB
+	testCases := []struct {
+		Name     string
+		From, To audio.Layout
+		Want     Matrix
+	}{
+		{"mono to stereo", audio.Mono, audio.Stereo, Matrix{{g}, {g}}},
+		{"stereo to mono", audio.Stereo, audio.Mono, Matrix{{0.5, 0.5}}},
+		{"5.1 to stereo", audio.Surround51, audio.Stereo, Matrix{
+			{1, 0, g, 0, g, 0},
+			{0, 1, g, 0, 0, g},
+		}},
+		{"5.1 to 5.1 (ALSA)", audio.Surround51, audio.Surround51ALSA, Matrix{
+			{1, 0, 0, 0, 0, 0},
+			{0, 1, 0, 0, 0, 0},
+			{0, 0, 0, 0, 1, 0},
+			{0, 0, 0, 0, 0, 1},
+			{0, 0, 1, 0, 0, 0},
+			{0, 0, 0, 1, 0, 0},
+		}},
+		{"7.1 to 5.1", audio.Surround71, audio.Surround51, Matrix{
+			{1, 0, 0, 0, 0, 0, 0, 0},
+			{0, 1, 0, 0, 0, 0, 0, 0},
+			{0, 0, 1, 0, 0, 0, 0, 0},
+			{0, 0, 0, 1, 0, 0, 0, 0},
+			{0, 0, 0, 0, 1, 0, 1, 0},
+			{0, 0, 0, 0, 0, 1, 0, 1},
+		}},
+		{"stereo to 2.1", audio.Stereo, audio.Stereo21, Matrix{{1, 0}, {0, 1}, {0, 0}}},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			m, err := NewMatrix(test.From, test.To)
+			if err != nil {
+				it.Fatal(err)
+			}
+			if len(m) != len(test.Want) {
+				it.Fatalf("expected %d outputs, got %d", len(test.Want), len(m))
+			}
+			for o, row := range test.Want {
+				for i, want := range row {
+					if v := m[o][i]; math.Abs(v-want) > 1e-12 {
+						it.Errorf("expected gain of %s in %s to be %g, got %g", test.From[i], test.To[o], want, v)
+					}
+				}
+			}
+		})
+	}
+
+	if _, err := NewMatrix(nil, audio.Stereo); err != ErrLayout {
+		t.Errorf("expected error %q, got %q", ErrLayout, err)
+	}
+}
+
+func TestMatrixNormalize(t *testing.T) {
+	m, err := NewMatrix(audio.Surround51, audio.Stereo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Normalize()
+	if v, want := m[0][0], 1/(1+2*minus3dB); math.Abs(v-want) > 1e-12 {
+		t.Errorf("expected front gain %g, got %g", want, v)
+	}
+}
+
+type testReader[T audio.Sample] audio.Samples[T]
+
+func (r *testReader[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	if len(*r) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(samples, *r)
+	*r = (*r)[n:]
+	return n, nil
+}
+
+func TestRemix(t *testing.T) {
+	var (
+		format = audio.FormatOf[int16](48000, 2, binary.LittleEndian)
+		src    = testReader[int16]{1000, -1000, 0x7fff, 0x7fff, -0x8000, 0}
+	)
+
+	m, err := NewMatrix(audio.Stereo, audio.Mono)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRemix[int16](&src, format, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := r.Format().Channels; v != 1 {
+		t.Errorf("expected 1 channel, got %d", v)
+	}
+
+	test := make(audio.Samples[int16], 4)
+	n, err := r.ReadSamples(test)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := audio.Samples[int16]{0, 0x7fff, -0x4000}
+	if n != len(want) {
+		t.Fatalf("expected %d samples, got %d", len(want), n)
+	}
+	for i, v := range want {
+		if test[i] != v {
+			t.Errorf("expected sample %d to be %d, got %d", i, v, test[i])
+		}
+	}
+	if _, err = r.ReadSamples(test); err != io.EOF {
+		t.Errorf("expected error %q, got %q", io.EOF, err)
+	}
+
+	if _, err = NewRemix[int16](&src, format, Matrix{{1, 1, 1}}); err != ErrMatrix {
+		t.Errorf("expected error %q, got %q", ErrMatrix, err)
+	}
+}
@@ -0,0 +1,266 @@
+package mix
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/scratch"
+)
+
+// Clipping is how the Mixer limits mixed samples to full scale.
+type Clipping int
+
+const (
+	// HardClip saturates samples at full scale.
+	HardClip Clipping = iota
+
+	// SoftClip passes samples up to softKnee unchanged and compresses louder samples smoothly
+	// towards full scale, which distorts less than HardClip.
+	SoftClip
+)
+
+func (c Clipping) String() string {
+	switch c {
+	case HardClip:
+		return "hard clip"
+	case SoftClip:
+		return "soft clip"
+	default:
+		return fmt.Sprintf("unknown clipping %d", c)
+	}
+}
+
+// softKnee is the level where SoftClip starts compressing, about -2 dBFS.
+const softKnee = 0.8
+
+// Mixer sums samples read from multiple inputs.
+//
+// The Mixer and its inputs are not safe for concurrent use.
+type Mixer[T audio.Sample] struct {
+	// Headroom in dB that the mix is attenuated by.
+	Headroom float64
+
+	// Clipping limits the mix to full scale.
+	Clipping Clipping
+
+	// Continuous mixers produce silence when all inputs have ended instead of returning io.EOF,
+	// so inputs can be added later.
+	Continuous bool
+
+	format audio.Format
+	layout audio.Layout
+	inputs []*Input[T]
+	in     audio.Samples[T]
+	src    audio.Samples[float64]
+	sum    audio.Samples[float64]
+}
+
+// Input of a Mixer.
+type Input[T audio.Sample] struct {
+	reader audio.Reader[T]
+	layout audio.Layout
+	gain   float64
+	pan    float64
+	gains  []float64
+	done   bool
+
+	// partial is the start of a frame that didn't fit in the last read.
+	partial audio.Samples[T]
+}
+
+// NewMixer returns a mixer for inputs with samples in the given format.
+func NewMixer[T audio.Sample](format audio.Format) (*Mixer[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	return &Mixer[T]{
+		format: format,
+		layout: audio.DefaultLayout(format.Channels),
+	}, nil
+}
+
+// Add an input with a gain of 0 dB and centered pan. The input is removed once r has ended.
+func (m *Mixer[T]) Add(r audio.Reader[T]) *Input[T] {
+	in := &Input[T]{
+		reader: r,
+		layout: m.layout,
+		gains:  make([]float64, m.format.Channels),
+	}
+	in.update()
+	m.inputs = append(m.inputs, in)
+	return in
+}
+
+// Inputs returns the number of inputs that haven't ended.
+func (m *Mixer[T]) Inputs() int {
+	return len(m.inputs)
+}
+
+// Format of the mixed samples.
+func (m *Mixer[T]) Format() audio.Format {
+	return m.format
+}
+
+func (m *Mixer[T]) String() string {
+	return fmt.Sprintf("mix %d inputs", len(m.inputs))
+}
+
+// ReadSamples mixes as many whole frames as fit in samples from all inputs.
+//
+// Inputs that end early are padded with silence, the mix ends when all inputs have ended. If an
+// input fails, the samples read from all inputs are still mixed and returned with the first error.
+func (m *Mixer[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	channels := m.format.Channels
+	samples = samples[:len(samples)-len(samples)%channels]
+	if len(samples) == 0 {
+		return 0, io.ErrShortBuffer
+	}
+
+	m.in = scratch.Grow(m.in, len(samples))
+	m.src = scratch.Grow(m.src, len(samples))
+	m.sum = scratch.Grow(m.sum, len(samples))
+	clear(m.sum)
+
+	var (
+		n   int
+		err error
+	)
+	for _, in := range m.inputs {
+		read, inErr := in.read(m.in)
+		if err == nil {
+			err = inErr
+		}
+		audio.Convert(m.src, m.in[:read])
+		for i, v := range m.src[:read] {
+			m.sum[i] += v * in.gains[i%channels]
+		}
+		n = max(n, read)
+	}
+	m.remove()
+
+	if m.Continuous && err == nil {
+		n = len(samples)
+	} else if n == 0 {
+		if err != nil {
+			return 0, err
+		}
+		return 0, io.EOF
+	}
+
+	m.limit(m.sum[:n])
+	audio.Convert(samples, m.sum[:n])
+	return n, err
+}
+
+// remove inputs that have ended.
+func (m *Mixer[T]) remove() {
+	inputs := m.inputs[:0]
+	for _, in := range m.inputs {
+		if !in.done {
+			inputs = append(inputs, in)
+		}
+	}
+	clear(m.inputs[len(inputs):])
+	m.inputs = inputs
+}
+
+// limit applies headroom and clipping.
+func (m *Mixer[T]) limit(samples audio.Samples[float64]) {
+	gain := math.Pow(10, -m.Headroom/20)
+	for i, v := range samples {
+		v *= gain
+		switch m.Clipping {
+		case SoftClip:
+			if a := math.Abs(v); a > softKnee {
+				v = math.Copysign(softKnee+(1-softKnee)*math.Tanh((a-softKnee)/(1-softKnee)), v)
+			}
+		default:
+			v = math.Max(-1, math.Min(1, v))
+		}
+		samples[i] = v
+	}
+}
+
+// SetGain sets the gain of the input in dB.
+func (in *Input[T]) SetGain(gain float64) {
+	in.gain = gain
+	in.update()
+}
+
+// Gain of the input in dB.
+func (in *Input[T]) Gain() float64 {
+	return in.gain
+}
+
+// SetPan sets the position of the input between left (-1) and right (1), which attenuates the
+// opposite side, by 3 dB halfway. Pan has no effect on layouts without sides.
+func (in *Input[T]) SetPan(pan float64) {
+	in.pan = math.Max(-1, math.Min(1, pan))
+	in.update()
+}
+
+// Pan of the input.
+func (in *Input[T]) Pan() float64 {
+	return in.pan
+}
+
+// Done checks if the input has ended.
+func (in *Input[T]) Done() bool {
+	return in.done
+}
+
+// update the channel gains.
+func (in *Input[T]) update() {
+	var (
+		gain  = math.Pow(10, in.gain/20)
+		left  = math.Min(1, math.Sqrt(1-in.pan))
+		right = math.Min(1, math.Sqrt(1+in.pan))
+	)
+	for i := range in.gains {
+		in.gains[i] = gain
+		if i < len(in.layout) {
+			switch in.layout[i] {
+			case audio.FrontLeft, audio.FrontLeftOfCenter, audio.BackLeft, audio.SideLeft:
+				in.gains[i] *= left
+			case audio.FrontRight, audio.FrontRightOfCenter, audio.BackRight, audio.SideRight:
+				in.gains[i] *= right
+			}
+		}
+	}
+}
+
+// read fills samples with whole frames, until the input ends or fails. A partial frame is kept
+// for the next read, or padded with silence when the input has ended.
+func (in *Input[T]) read(samples audio.Samples[T]) (int, error) {
+	channels := len(in.gains)
+	n := copy(samples, in.partial)
+	in.partial = in.partial[:0]
+
+	var err error
+	for n < len(samples) && !in.done {
+		var m int
+		m, err = in.reader.ReadSamples(samples[n:])
+		n += m
+		if err == io.EOF {
+			in.done, err = true, nil
+		} else if err != nil {
+			break
+		} else if m == 0 {
+			err = io.ErrNoProgress
+			break
+		}
+	}
+
+	if rest := n % channels; rest > 0 {
+		if in.done {
+			clear(samples[n : n+channels-rest])
+			n += channels - rest
+		} else {
+			in.partial = append(in.partial, samples[n-rest:n]...)
+			n -= rest
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,156 @@
+package mix
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+)
+
+func TestMixer(t *testing.T) {
+	format := audio.FormatOf[int16](48000, 2, binary.LittleEndian)
+	m, err := NewMixer[int16](format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		long  = testReader[int16]{1000, 1000, 1000, 1000, 1000, 1000}
+		short = testReader[int16]{2000, 2000}
+	)
+	m.Add(&long)
+	in := m.Add(&short)
+	in.SetGain(-6.0206) // halve
+	in.SetPan(1)
+
+	test := make(audio.Samples[int16], 4)
+	n, err := m.ReadSamples(test)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := audio.Samples[int16]{1000, 2000, 1000, 1000}
+	if n != len(want) {
+		t.Fatalf("expected %d samples, got %d", len(want), n)
+	}
+	for i, v := range want {
+		if test[i] != v {
+			t.Errorf("expected sample %d to be %d, got %d", i, v, test[i])
+		}
+	}
+	if !in.Done() || m.Inputs() != 1 {
+		t.Errorf("expected the short input to be removed, got %d inputs", m.Inputs())
+	}
+
+	if n, err = m.ReadSamples(test); err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Errorf("expected 2 samples, got %d", n)
+	}
+	if _, err = m.ReadSamples(test); err != io.EOF {
+		t.Errorf("expected error %q, got %q", io.EOF, err)
+	}
+
+	m.Continuous = true
+	if n, err = m.ReadSamples(test); err != nil {
+		t.Fatal(err)
+	} else if n != len(test) {
+		t.Errorf("expected %d samples of silence, got %d", len(test), n)
+	}
+}
+
+func TestMixerClipping(t *testing.T) {
+	format := audio.FormatOf[float64](48000, 1, binary.LittleEndian)
+	testCases := []struct {
+		Mode     Clipping
+		Headroom float64
+		Want     audio.Samples[float64]
+	}{
+		{HardClip, 0, audio.Samples[float64]{0.5, 1, -1, 0.25}},
+		{SoftClip, 0, audio.Samples[float64]{0.5, 0.8 + 0.2*math.Tanh(2), -0.8 - 0.2*math.Tanh(2), 0.25}},
+		{HardClip, 6.0206, audio.Samples[float64]{0.25, 0.6, -0.6, 0.125}},
+	}
+	for _, test := range testCases {
+		t.Run(test.Mode.String(), func(it *testing.T) {
+			m, err := NewMixer[float64](format)
+			if err != nil {
+				it.Fatal(err)
+			}
+			m.Clipping = test.Mode
+			m.Headroom = test.Headroom
+			for range 2 {
+				m.Add(&testReader[float64]{0.25, 0.6, -0.6, 0.125})
+			}
+
+			mixed := make(audio.Samples[float64], 4)
+			if _, err = m.ReadSamples(mixed); err != nil {
+				it.Fatal(err)
+			}
+			for i, v := range test.Want {
+				if math.Abs(mixed[i]-v) > 1e-4 {
+					it.Errorf("expected sample %d to be %g, got %g", i, v, mixed[i])
+				}
+			}
+		})
+	}
+}
+
+// testStep is the result of one read of a testSteps reader.
+type testStep struct {
+	samples audio.Samples[int16]
+	err     error
+}
+
+// testSteps returns the steps in order, and io.EOF when there are no more steps.
+type testSteps []testStep
+
+func (r *testSteps) ReadSamples(samples audio.Samples[int16]) (int, error) {
+	if len(*r) == 0 {
+		return 0, io.EOF
+	}
+	step := (*r)[0]
+	*r = (*r)[1:]
+	return copy(samples, step.samples), step.err
+}
+
+func TestMixerInputs(t *testing.T) {
+	var (
+		format = audio.FormatOf[int16](48000, 2, binary.LittleEndian)
+		failed = errors.New("failed")
+	)
+	testCases := []struct {
+		Name  string
+		Steps testSteps
+		Reads []testStep
+	}{
+		{"partial frame", testSteps{{samples: audio.Samples[int16]{1, 2, 3}}, {err: failed}, {samples: audio.Samples[int16]{4, 5, 6}}},
+			[]testStep{{audio.Samples[int16]{1, 2}, failed}, {audio.Samples[int16]{3, 4, 5, 6}, nil}}},
+		{"no progress", testSteps{{samples: audio.Samples[int16]{1, 2}}, {}},
+			[]testStep{{audio.Samples[int16]{1, 2}, io.ErrNoProgress}}},
+		{"padded at the end", testSteps{{samples: audio.Samples[int16]{1, 2, 3}}},
+			[]testStep{{audio.Samples[int16]{1, 2, 3, 0}, nil}, {nil, io.EOF}}},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			m, err := NewMixer[int16](format)
+			if err != nil {
+				it.Fatal(err)
+			}
+			m.Add(&test.Steps)
+			for i, want := range test.Reads {
+				samples := make(audio.Samples[int16], 4)
+				n, err := m.ReadSamples(samples)
+				if err != want.err {
+					it.Errorf("read %d: expected error %v, got %v", i, want.err, err)
+				}
+				if !slices.Equal(samples[:n], want.samples) {
+					it.Errorf("read %d: expected %v, got %v", i, want.samples, samples[:n])
+				}
+			}
+		})
+	}
+}
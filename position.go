@@ -0,0 +1,95 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrSeek   = errors.New("audio: source can't seek")
+	errWhence = errors.New("audio: invalid whence")
+	errOffset = errors.New("audio: negative position")
+)
+
+// FrameSeeker can seek to a frame, whence is io.SeekStart, io.SeekCurrent or io.SeekEnd like
+// for io.Seeker.
+type FrameSeeker interface {
+	SeekFrame(frame int64, whence int) (int64, error)
+}
+
+// Position tracks the position of samples read from a Reader.
+//
+// If the Reader is a FrameSeeker, like readers returned by NewReader for an io.ReadSeeker,
+// Position can seek to a frame or a time.
+type Position[T Sample] struct {
+	reader Reader[T]
+	format Format
+	frame  int64
+
+	// partial is the number of samples of the current frame that have been read.
+	partial int
+}
+
+// NewPosition tracks the position of samples in the given format read from r, starting at frame 0.
+func NewPosition[T Sample](r Reader[T], format Format) (*Position[T], error) {
+	if format.Channels < 1 {
+		return nil, ErrChannels
+	}
+	if format.SampleRate < 1 {
+		return nil, ErrSampleRate
+	}
+	return &Position[T]{
+		reader: r,
+		format: format,
+	}, nil
+}
+
+// Format of the samples.
+func (p *Position[T]) Format() Format {
+	return p.format
+}
+
+func (p *Position[T]) String() string {
+	return fmt.Sprintf("position %s", p.Elapsed())
+}
+
+func (p *Position[T]) ReadSamples(samples Samples[T]) (int, error) {
+	n, err := p.reader.ReadSamples(samples)
+	p.partial += n
+	p.frame += int64(p.partial / p.format.Channels)
+	p.partial %= p.format.Channels
+	return n, err
+}
+
+// Frame returns the number of whole frames read.
+func (p *Position[T]) Frame() int64 {
+	return p.frame
+}
+
+// Elapsed returns the stream time of the frames read.
+func (p *Position[T]) Elapsed() time.Duration {
+	return p.format.Duration(p.frame)
+}
+
+// SeekFrame seeks to a frame relative to whence, and returns the new frame. Frames are counted
+// by the Reader after seeking. Seeking discards a partially read frame, so reads continue at the
+// start of a frame.
+func (p *Position[T]) SeekFrame(frame int64, whence int) (int64, error) {
+	s, ok := p.reader.(FrameSeeker)
+	if !ok {
+		return p.frame, ErrSeek
+	}
+	frame, err := s.SeekFrame(frame, whence)
+	if err != nil {
+		return p.frame, err
+	}
+	p.frame, p.partial = frame, 0
+	return frame, nil
+}
+
+// Seek seeks to the frame nearest to a time relative to whence, and returns the new stream time.
+func (p *Position[T]) Seek(offset time.Duration, whence int) (time.Duration, error) {
+	frame, err := p.SeekFrame(int64(p.format.Frames(offset)), whence)
+	return p.format.Duration(frame), err
+}
@@ -0,0 +1,101 @@
+package audio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/BeatGlow/audio"
+)
+
+func TestPosition(t *testing.T) {
+	format := audio.FormatOf[int16](1000, 2, binary.LittleEndian)
+
+	// 100 frames, where each sample is the frame number.
+	data := make([]byte, 100*format.BytesPerFrame())
+	for i := range 100 {
+		binary.LittleEndian.PutUint16(data[i*4:], uint16(i))
+		binary.LittleEndian.PutUint16(data[i*4+2:], uint16(i))
+	}
+
+	// Frame 0 is the position of the io.Reader when the reader is created, like after a header.
+	rs := bytes.NewReader(append([]byte{0xff, 0xff, 0xff}, data...))
+	if _, err := rs.Seek(3, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	r, err := audio.NewReader[int16](rs, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := audio.NewPosition[int16](r, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	samples := make(audio.Samples[int16], 21)
+	if _, err = p.ReadSamples(samples); err != nil {
+		t.Fatal(err)
+	}
+	if v := p.Frame(); v != 10 {
+		t.Errorf("expected frame 10, got %d", v)
+	}
+	if v := p.Elapsed(); v != 10*time.Millisecond {
+		t.Errorf("expected 10ms elapsed, got %s", v)
+	}
+
+	// Seeking from the current position discards the partial frame.
+	if v, err := p.SeekFrame(5, io.SeekCurrent); err != nil || v != 15 {
+		t.Fatalf("expected frame 15, got %d, %v", v, err)
+	}
+	if _, err = p.ReadSamples(samples[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if samples[0] != 15 || samples[1] != 15 {
+		t.Errorf("expected frame 15, got %v", samples[:2])
+	}
+
+	if v, err := p.Seek(50*time.Millisecond, io.SeekStart); err != nil || v != 50*time.Millisecond {
+		t.Fatalf("expected 50ms, got %s, %v", v, err)
+	}
+	if _, err = p.ReadSamples(samples[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if samples[0] != 50 {
+		t.Errorf("expected frame 50, got %v", samples[:2])
+	}
+
+	if v, err := p.SeekFrame(-10, io.SeekEnd); err != nil || v != 90 {
+		t.Fatalf("expected frame 90, got %d, %v", v, err)
+	}
+	if v := p.Frame(); v != 90 {
+		t.Errorf("expected frame 90, got %d", v)
+	}
+
+	if _, err = p.SeekFrame(-1, io.SeekStart); err == nil {
+		t.Error("expected error seeking before the start")
+	}
+}
+
+func TestPositionNotSeekable(t *testing.T) {
+	format := audio.FormatOf[int16](1000, 1, binary.LittleEndian)
+	r, err := audio.NewReader[int16](testZero{}, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := audio.NewPosition[int16](r, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = p.ReadSamples(make(audio.Samples[int16], 1000)); err != nil {
+		t.Fatal(err)
+	}
+	if v := p.Elapsed(); v != time.Second {
+		t.Errorf("expected 1s elapsed, got %s", v)
+	}
+	if _, err = p.SeekFrame(0, io.SeekStart); !errors.Is(err, audio.ErrSeek) {
+		t.Errorf("expected ErrSeek, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+// Package resample converts the sample rate of audio streams.
+package resample
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/dsp/filter"
+	"github.com/BeatGlow/audio/dsp/window"
+	"github.com/BeatGlow/audio/internal/scratch"
+)
+
+var (
+	ErrQuality = errors.New("resample: unknown quality")
+	ErrRatio   = errors.New("resample: ratio must be positive")
+)
+
+// Quality of the resampling filter, which trades CPU time for a wider passband and more
+// attenuation of aliases and images.
+type Quality int
+
+const (
+	// Low quality has a passband up to 75% of the Nyquist frequency.
+	Low Quality = iota
+
+	// Medium quality has a passband up to 80% of the Nyquist frequency.
+	Medium
+
+	// High quality has a passband up to 90% of the Nyquist frequency.
+	High
+)
+
+func (q Quality) String() string {
+	switch q {
+	case Low:
+		return "low"
+	case Medium:
+		return "medium"
+	case High:
+		return "high"
+	default:
+		return fmt.Sprintf("unknown quality %d", q)
+	}
+}
+
+// preset is the filter design of a Quality.
+type preset struct {
+	// taps is the number of input frames per output frame.
+	taps int
+
+	// phases is the number of filter phases between two input frames, intermediate phases are
+	// linearly interpolated.
+	phases int
+
+	// rolloff is the cutoff frequency relative to the Nyquist frequency.
+	rolloff float64
+
+	window window.GeneratorFunc
+}
+
+var presets = map[Quality]preset{
+	Low:    {taps: 16, phases: 64, rolloff: 0.75, window: window.Hann},
+	Medium: {taps: 32, phases: 128, rolloff: 0.8, window: window.Blackman},
+	High:   {taps: 64, phases: 256, rolloff: 0.9, window: window.Blackman},
+}
+
+// Resampler is a polyphase windowed sinc sample rate converter.
+type Resampler[T audio.Sample] struct {
+	reader  audio.Reader[T]
+	format  audio.Format
+	input   int
+	quality Quality
+
+	// kernel is the prototype low pass filter, with phases coefficients per input frame.
+	kernel []float64
+	taps   int
+	phases int
+
+	// step is the number of input frames per output frame, as the fraction num/den.
+	num, den uint64
+
+	// history contains interleaved input frames, the next output frame is at the position
+	// base+frac/den and end is the position after the last input frame, once the input has ended.
+	history []float64
+	base    int
+	frac    uint64
+	end     int
+
+	in           audio.Samples[T]
+	out          audio.Samples[float64]
+	coefficients []float64
+}
+
+// New converts samples in the given format read from r to sampleRate.
+func New[T audio.Sample](r audio.Reader[T], format audio.Format, sampleRate int, quality Quality) (*Resampler[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	if sampleRate < 1 {
+		return nil, audio.ErrSampleRate
+	}
+	p, ok := presets[quality]
+	if !ok {
+		return nil, ErrQuality
+	}
+
+	// When downsampling, the filter is stretched to keep the transition band the same relative
+	// to the output rate.
+	var (
+		scale = math.Min(1, float64(sampleRate)/float64(format.SampleRate))
+		taps  = 2 * int(math.Ceil(float64(p.taps)/scale/2))
+		sinc  = &filter.Sinc{
+			CutOffFrequency: p.rolloff * scale * float64(format.SampleRate) / 2,
+			SampleRate:      p.phases * format.SampleRate,
+			Taps:            p.phases * taps,
+			Window:          p.window,
+		}
+		kernel = sinc.LowPassCoefficients()
+	)
+	for i := range kernel {
+		kernel[i] *= float64(p.phases)
+	}
+
+	output := format
+	output.SampleRate = sampleRate
+	g := gcd(format.SampleRate, sampleRate)
+	return &Resampler[T]{
+		reader:       r,
+		format:       output,
+		input:        format.SampleRate,
+		quality:      quality,
+		kernel:       kernel,
+		taps:         taps,
+		phases:       p.phases,
+		num:          uint64(format.SampleRate / g),
+		den:          uint64(sampleRate / g),
+		history:      make([]float64, taps/2*format.Channels),
+		base:         taps / 2,
+		end:          -1,
+		coefficients: make([]float64, taps),
+	}, nil
+}
+
+// Format of the resampled samples.
+func (r *Resampler[T]) Format() audio.Format {
+	return r.format
+}
+
+func (r *Resampler[T]) String() string {
+	return fmt.Sprintf("resample %d → %d Hz, %s quality", r.input, r.format.SampleRate, r.quality)
+}
+
+// Ratio returns the number of output frames per input frame.
+func (r *Resampler[T]) Ratio() float64 {
+	return float64(r.den) / float64(r.num)
+}
+
+// SetRatio changes the number of output frames per input frame, which can be used to follow
+// drifting clocks. The filter isn't redesigned, so the ratio should stay close to the ratio of
+// the sample rates.
+func (r *Resampler[T]) SetRatio(ratio float64) error {
+	if !(ratio > 0) || math.IsInf(ratio, 0) {
+		return ErrRatio
+	}
+	const den = 1 << 32
+	r.frac = r.frac * den / r.den
+	r.num, r.den = uint64(math.Round(den/ratio)), den
+	return nil
+}
+
+// ReadSamples reads as many whole frames as fit in samples.
+func (r *Resampler[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	var (
+		channels = r.format.Channels
+		frames   = len(samples) / channels
+		half     = r.taps / 2
+	)
+	if frames == 0 {
+		return 0, io.ErrShortBuffer
+	}
+
+	r.out = scratch.Grow(r.out, frames*channels)
+	var n int
+	for n < frames {
+		if r.end >= 0 && r.base >= r.end {
+			break
+		}
+
+		if r.base+half >= len(r.history)/channels {
+			if err := r.fill(frames - n); err != nil {
+				audio.Convert(samples, r.out[:n*channels])
+				return n * channels, err
+			}
+			continue
+		}
+
+		r.interpolate(r.out[n*channels : (n+1)*channels])
+		r.frac += r.num
+		r.base += int(r.frac / r.den)
+		r.frac %= r.den
+		n++
+	}
+
+	audio.Convert(samples, r.out[:n*channels])
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return n * channels, nil
+}
+
+// interpolate computes the output frame at the current position.
+func (r *Resampler[T]) interpolate(frame []float64) {
+	var (
+		channels = len(frame)
+		x        = float64(r.frac) / float64(r.den) * float64(r.phases)
+		phase    = int(x)
+		a        = x - float64(phase)
+	)
+	for m := range r.coefficients {
+		k := phase + r.phases*(r.taps-1-m)
+		r.coefficients[m] = r.kernel[k] + a*(r.kernel[k+1]-r.kernel[k])
+	}
+
+	history := r.history[(r.base-r.taps/2+1)*channels:]
+	for c := range frame {
+		var v float64
+		for m, h := range r.coefficients {
+			v += h * history[m*channels+c]
+		}
+		frame[c] = v
+	}
+}
+
+// fill discards input frames that are no longer needed and reads enough input frames for the
+// requested number of output frames.
+func (r *Resampler[T]) fill(frames int) error {
+	channels := r.format.Channels
+	if r.end >= 0 {
+		// Input has ended and the history is padded, nothing left.
+		return io.EOF
+	}
+
+	if drop := r.base - r.taps/2 + 1; drop > 0 {
+		r.history = r.history[:copy(r.history, r.history[drop*channels:])]
+		r.base -= drop
+	}
+
+	want := int(uint64(frames)*r.num/r.den) + 1
+	r.in = scratch.Grow(r.in, want*channels)
+	n, err := r.reader.ReadSamples(r.in)
+	n -= n % channels
+
+	offset := len(r.history)
+	r.history = slices.Grow(r.history, n)[:offset+n]
+	audio.Convert(r.history[offset:], r.in[:n])
+
+	if err == io.EOF {
+		// Pad with silence to flush the filter.
+		r.end = len(r.history) / channels
+		offset, n = len(r.history), r.taps/2*channels
+		r.history = slices.Grow(r.history, n)[:offset+n]
+		clear(r.history[offset:])
+		return nil
+	}
+	return err
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
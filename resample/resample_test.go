@@ -0,0 +1,167 @@
+package resample
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+)
+
+type testReader audio.Samples[float64]
+
+func (r *testReader) ReadSamples(samples audio.Samples[float64]) (int, error) {
+	if len(*r) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(samples, *r)
+	*r = (*r)[n:]
+	return n, nil
+}
+
+// testResample resamples a mono sine wave with the given frequency and returns the output.
+func testResample(t *testing.T, from, to int, frequency float64, quality Quality) audio.Samples[float64] {
+	t.Helper()
+
+	src := make(testReader, from)
+	for i := range src {
+		src[i] = 0.5 * math.Sin(2*math.Pi*frequency*float64(i)/float64(from))
+	}
+	r, err := New[float64](&src, audio.FormatOf[float64](from, 1, binary.NativeEndian), to, quality)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		out    audio.Samples[float64]
+		buffer = make(audio.Samples[float64], 1000)
+	)
+	for {
+		n, err := r.ReadSamples(buffer)
+		out = append(out, buffer[:n]...)
+		if err == io.EOF {
+			return out
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// testLevel returns the level in dB relative to the input sine at the given frequency, measured
+// with a Blackman window over the middle of the signal.
+func testLevel(s audio.Samples[float64], frequency float64, sampleRate int) float64 {
+	const size = 8192
+	s = s[(len(s)-size)/2:][:size]
+
+	var (
+		sum  complex128
+		gain float64
+	)
+	for i, v := range s {
+		w := 0.42 - 0.5*math.Cos(2*math.Pi*float64(i)/(size-1)) + 0.08*math.Cos(4*math.Pi*float64(i)/(size-1))
+		sum += complex(v*w, 0) * cmplx.Exp(complex(0, -2*math.Pi*frequency*float64(i)/float64(sampleRate)))
+		gain += w
+	}
+	return 20 * math.Log10(2*cmplx.Abs(sum)/gain/0.5)
+}
+
+func TestResampleLength(t *testing.T) {
+	for _, test := range []struct{ From, To int }{{44100, 48000}, {48000, 44100}, {8000, 48000}, {48000, 8000}} {
+		out := testResample(t, test.From, test.To, 1000, Medium)
+		if len(out) != test.To {
+			t.Errorf("expected %d to %d Hz to produce %d frames, got %d", test.From, test.To, test.To, len(out))
+		}
+	}
+}
+
+func TestResamplePassband(t *testing.T) {
+	testCases := []struct {
+		Quality Quality
+		Ripple  float64
+	}{
+		{Low, 0.5},
+		{Medium, 0.01},
+		{High, 0.01},
+	}
+	for _, test := range testCases {
+		t.Run(test.Quality.String(), func(it *testing.T) {
+			p := presets[test.Quality]
+			for _, rates := range [][2]int{{44100, 48000}, {48000, 44100}} {
+				edge := p.rolloff * float64(min(rates[0], rates[1])) / 2
+				var lo, hi = math.Inf(1), math.Inf(-1)
+				for f := 100.0; f < edge*0.8; f += 1000 {
+					v := testLevel(testResample(it, rates[0], rates[1], f, test.Quality), f, rates[1])
+					lo, hi = math.Min(lo, v), math.Max(hi, v)
+				}
+				it.Logf("%d to %d Hz: passband ripple %.4f dB", rates[0], rates[1], hi-lo)
+				if hi-lo > test.Ripple || math.Abs(hi) > test.Ripple {
+					it.Errorf("%d to %d Hz: expected passband ripple within %g dB, got %.4f to %.4f dB", rates[0], rates[1], test.Ripple, lo, hi)
+				}
+			}
+		})
+	}
+}
+
+func TestResampleStopband(t *testing.T) {
+	testCases := []struct {
+		Quality     Quality
+		Attenuation float64
+	}{
+		{Low, 40},
+		{Medium, 70},
+		{High, 90},
+	}
+	for _, test := range testCases {
+		t.Run(test.Quality.String(), func(it *testing.T) {
+			// Upsampling: the image of a tone at 44100-f folds back to f+3900 Hz.
+			for _, f := range []float64{1000, 5000, 10000} {
+				v := testLevel(testResample(it, 44100, 48000, f, test.Quality), 48000-(44100-f), 48000)
+				it.Logf("44100 to 48000 Hz: image of %g Hz at %.2f dB", f, v)
+				if v > -test.Attenuation {
+					it.Errorf("expected the image of %g Hz to be attenuated by %g dB, got %.2f dB", f, test.Attenuation, -v)
+				}
+			}
+
+			// Downsampling: a tone above the output Nyquist frequency aliases to 44100-f.
+			for _, f := range []float64{23000, 30000} {
+				v := testLevel(testResample(it, 48000, 44100, f, test.Quality), 44100-f, 44100)
+				it.Logf("48000 to 44100 Hz: alias of %g Hz at %.2f dB", f, v)
+				if v > -test.Attenuation {
+					it.Errorf("expected the alias of %g Hz to be attenuated by %g dB, got %.2f dB", f, test.Attenuation, -v)
+				}
+			}
+		})
+	}
+}
+
+func TestResampleRatio(t *testing.T) {
+	src := make(testReader, 48000*2)
+	r, err := New[float64](&src, audio.FormatOf[float64](48000, 2, binary.NativeEndian), 48000, Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.SetRatio(0); err != ErrRatio {
+		t.Errorf("expected error %q, got %q", ErrRatio, err)
+	}
+	if err = r.SetRatio(1.001); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		frames int
+		buffer = make(audio.Samples[float64], 1024)
+	)
+	for {
+		n, err := r.ReadSamples(buffer)
+		frames += n / 2
+		if err != nil {
+			break
+		}
+	}
+	// The ratio is approximated by a fraction, which may give an extra frame.
+	if frames < 48048 || frames > 48049 {
+		t.Errorf("expected 48048 frames, got %d", frames)
+	}
+}
@@ -0,0 +1,339 @@
+// Package ring implements a lock-free single-producer single-consumer ring buffer of samples.
+package ring
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/scratch"
+)
+
+var (
+	ErrFrames  = errors.New("ring: need room for at least one frame")
+	ErrOverrun = errors.New("ring: buffer overrun, samples were dropped")
+
+	// ErrChannels is returned when the channels of an audio.Buffer don't match the ring buffer.
+	ErrChannels = errors.New("ring: number of channels doesn't match")
+)
+
+// Buffer is a ring buffer of interleaved samples, that can be written to by one goroutine and
+// read from by another goroutine without locking.
+//
+// All reads and writes are aligned to whole frames. The Try methods are wait-free, the other
+// methods block until they are done or the buffer is closed.
+type Buffer[T audio.Sample] struct {
+	format   audio.Format
+	data     audio.Samples[T]
+	read     atomic.Uint64
+	write    atomic.Uint64
+	closed   atomic.Bool
+	done     chan struct{}
+	readable chan struct{}
+	writable chan struct{}
+
+	overruns  atomic.Uint64
+	underruns atomic.Uint64
+
+	// scratch buffers, for the consumer and producer respectively
+	readSamples  audio.Samples[T]
+	writeSamples audio.Samples[T]
+}
+
+// Stats are the statistics of a Buffer.
+type Stats struct {
+	// Overruns is the number of non-blocking writes that dropped samples because the buffer was full.
+	Overruns uint64
+
+	// Underruns is the number of non-blocking reads that returned less samples than requested
+	// because the buffer was empty.
+	Underruns uint64
+}
+
+// New returns a ring buffer that can hold frames frames of samples in the given format.
+func New[T audio.Sample](format audio.Format, frames int) (*Buffer[T], error) {
+	if format.Channels < 1 {
+		return nil, audio.ErrChannels
+	}
+	if frames < 1 {
+		return nil, ErrFrames
+	}
+	return &Buffer[T]{
+		format:   format,
+		data:     make(audio.Samples[T], frames*format.Channels),
+		done:     make(chan struct{}),
+		readable: make(chan struct{}, 1),
+		writable: make(chan struct{}, 1),
+	}, nil
+}
+
+// Format of the samples in the buffer.
+func (b *Buffer[T]) Format() audio.Format {
+	return b.format
+}
+
+// Cap is the capacity of the buffer in frames.
+func (b *Buffer[T]) Cap() int {
+	return len(b.data) / b.format.Channels
+}
+
+// Len is the number of frames that can be read.
+func (b *Buffer[T]) Len() int {
+	return int(b.write.Load()-b.read.Load()) / b.format.Channels
+}
+
+// Stats returns the overrun and underrun counters.
+func (b *Buffer[T]) Stats() Stats {
+	return Stats{
+		Overruns:  b.overruns.Load(),
+		Underruns: b.underruns.Load(),
+	}
+}
+
+// Close the buffer, after which writes fail and reads return io.EOF once the buffer is drained.
+//
+// Close should be called by the producer. Closing a closed buffer does nothing.
+func (b *Buffer[T]) Close() error {
+	if !b.closed.Swap(true) {
+		close(b.done)
+	}
+	return nil
+}
+
+// TryWriteSamples writes as many whole frames of samples as fit in the buffer without blocking.
+// If not all samples fit, the remaining samples are dropped and ErrOverrun is returned.
+func (b *Buffer[T]) TryWriteSamples(samples audio.Samples[T]) (int, error) {
+	if b.closed.Load() {
+		return 0, io.ErrClosedPipe
+	}
+	n := b.put(samples)
+	if n < len(samples)-len(samples)%b.format.Channels {
+		b.overruns.Add(1)
+		return n, ErrOverrun
+	}
+	return n, nil
+}
+
+// WriteSamples writes all whole frames of samples, waiting for room in the buffer if needed.
+func (b *Buffer[T]) WriteSamples(samples audio.Samples[T]) (int, error) {
+	var (
+		n    int
+		want = len(samples) - len(samples)%b.format.Channels
+	)
+	for {
+		if b.closed.Load() {
+			return n, io.ErrClosedPipe
+		}
+		if n += b.put(samples[n:want]); n == want {
+			return n, nil
+		}
+		select {
+		case <-b.writable:
+		case <-b.done:
+		}
+	}
+}
+
+// TryReadSamples reads as many whole frames as available into samples without blocking.
+//
+// If the buffer is closed and drained, the error is io.EOF. Reading the last frames of a closed
+// buffer isn't an underrun.
+func (b *Buffer[T]) TryReadSamples(samples audio.Samples[T]) (int, error) {
+	n := b.get(samples)
+	if b.closed.Load() && b.Len() == 0 {
+		if n == 0 {
+			return 0, io.EOF
+		}
+		return n, nil
+	}
+	if n < len(samples)-len(samples)%b.format.Channels {
+		b.underruns.Add(1)
+	}
+	return n, nil
+}
+
+// ReadSamples fills samples with whole frames, waiting for the producer if needed.
+//
+// If the buffer is closed, the remaining frames are returned and the error is io.EOF once the
+// buffer is drained.
+func (b *Buffer[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	var (
+		n    int
+		want = len(samples) - len(samples)%b.format.Channels
+	)
+	if want == 0 {
+		return 0, io.ErrShortBuffer
+	}
+	for {
+		if n += b.get(samples[n:want]); n == want {
+			return n, nil
+		}
+		if b.closed.Load() && b.Len() == 0 {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, io.EOF
+		}
+		select {
+		case <-b.readable:
+		case <-b.done:
+		}
+	}
+}
+
+// WriteBuffer writes all samples of the deinterleaved buffer, waiting for room if needed.
+func (b *Buffer[T]) WriteBuffer(buffer audio.Buffer[T]) (int, error) {
+	samples, err := b.interleave(buffer)
+	if err != nil {
+		return 0, err
+	}
+	n, err := b.WriteSamples(samples)
+	return n / b.format.Channels, err
+}
+
+// TryWriteBuffer writes as many samples of the deinterleaved buffer as fit without blocking.
+func (b *Buffer[T]) TryWriteBuffer(buffer audio.Buffer[T]) (int, error) {
+	samples, err := b.interleave(buffer)
+	if err != nil {
+		return 0, err
+	}
+	n, err := b.TryWriteSamples(samples)
+	return n / b.format.Channels, err
+}
+
+// ReadBuffer fills each channel of the deinterleaved buffer, waiting for the producer if needed.
+func (b *Buffer[T]) ReadBuffer(buffer audio.Buffer[T]) (int, error) {
+	samples, err := b.scratch(buffer)
+	if err != nil {
+		return 0, err
+	}
+	n, err := b.ReadSamples(samples)
+	audio.Deinterleave(buffer, samples[:n], b.format.Channels)
+	return n / b.format.Channels, err
+}
+
+// TryReadBuffer reads as many samples as available into each channel of the deinterleaved
+// buffer without blocking.
+func (b *Buffer[T]) TryReadBuffer(buffer audio.Buffer[T]) (int, error) {
+	samples, err := b.scratch(buffer)
+	if err != nil {
+		return 0, err
+	}
+	n, err := b.TryReadSamples(samples)
+	audio.Deinterleave(buffer, samples[:n], b.format.Channels)
+	return n / b.format.Channels, err
+}
+
+// NonBlocking returns a Reader and Writer for the buffer that don't block.
+func (b *Buffer[T]) NonBlocking() *NonBlocking[T] {
+	return &NonBlocking[T]{b}
+}
+
+// NonBlocking is a view of a Buffer where reads and writes don't block.
+type NonBlocking[T audio.Sample] struct {
+	b *Buffer[T]
+}
+
+// Format of the samples in the buffer.
+func (nb *NonBlocking[T]) Format() audio.Format { return nb.b.format }
+
+// ReadSamples calls TryReadSamples.
+func (nb *NonBlocking[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	return nb.b.TryReadSamples(samples)
+}
+
+// WriteSamples calls TryWriteSamples.
+func (nb *NonBlocking[T]) WriteSamples(samples audio.Samples[T]) (int, error) {
+	return nb.b.TryWriteSamples(samples)
+}
+
+// ReadBuffer calls TryReadBuffer.
+func (nb *NonBlocking[T]) ReadBuffer(buffer audio.Buffer[T]) (int, error) {
+	return nb.b.TryReadBuffer(buffer)
+}
+
+// WriteBuffer calls TryWriteBuffer.
+func (nb *NonBlocking[T]) WriteBuffer(buffer audio.Buffer[T]) (int, error) {
+	return nb.b.TryWriteBuffer(buffer)
+}
+
+// put copies as many whole frames as fit into the buffer, it must only be called by the producer.
+func (b *Buffer[T]) put(samples audio.Samples[T]) int {
+	var (
+		w    = b.write.Load()
+		free = len(b.data) - int(w-b.read.Load())
+		n    = min(free, len(samples))
+	)
+	if n -= n % b.format.Channels; n == 0 {
+		return 0
+	}
+
+	i := int(w % uint64(len(b.data)))
+	m := copy(b.data[i:], samples[:n])
+	copy(b.data, samples[m:n])
+
+	b.write.Store(w + uint64(n))
+	notify(b.readable)
+	return n
+}
+
+// get copies as many whole frames as available from the buffer, it must only be called by the
+// consumer.
+func (b *Buffer[T]) get(samples audio.Samples[T]) int {
+	var (
+		r     = b.read.Load()
+		avail = int(b.write.Load() - r)
+		n     = min(avail, len(samples))
+	)
+	if n -= n % b.format.Channels; n == 0 {
+		return 0
+	}
+
+	i := int(r % uint64(len(b.data)))
+	m := copy(samples[:n], b.data[i:])
+	copy(samples[m:n], b.data)
+
+	b.read.Store(r + uint64(n))
+	notify(b.writable)
+	return n
+}
+
+// interleave buffer into the scratch buffer of the producer.
+func (b *Buffer[T]) interleave(buffer audio.Buffer[T]) (audio.Samples[T], error) {
+	channels := b.format.Channels
+	if len(buffer) != channels {
+		return nil, fmt.Errorf("%w: expected %d, got %d", ErrChannels, channels, len(buffer))
+	}
+
+	frames := buffer.Samples()
+	b.writeSamples = scratch.Grow(b.writeSamples, frames*channels)
+	samples := b.writeSamples
+	for c, channel := range buffer {
+		for i, v := range channel[:frames] {
+			samples[i*channels+c] = v
+		}
+	}
+	return samples, nil
+}
+
+// scratch returns the scratch buffer of the consumer, sized to fit buffer.
+func (b *Buffer[T]) scratch(buffer audio.Buffer[T]) (audio.Samples[T], error) {
+	channels := b.format.Channels
+	if len(buffer) != channels {
+		return nil, fmt.Errorf("%w: expected %d, got %d", ErrChannels, channels, len(buffer))
+	}
+
+	size := buffer.Samples() * channels
+	b.readSamples = scratch.Grow(b.readSamples, size)
+	return b.readSamples, nil
+}
+
+// notify wakes up a waiting goroutine, if any.
+func notify(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,165 @@
+package ring
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+)
+
+func TestBufferConcurrent(t *testing.T) {
+	const total = 100000
+
+	b, err := New[int32](audio.FormatOf[int32](48000, 2, binary.LittleEndian), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		samples := make(audio.Samples[int32], 26)
+		for i := 0; i < total; i += len(samples) {
+			samples = samples[:min(len(samples), total-i)]
+			for j := range samples {
+				samples[j] = int32(i + j)
+			}
+			if _, err := b.WriteSamples(samples); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		_ = b.Close()
+	}()
+
+	var (
+		samples = make(audio.Samples[int32], 30)
+		next    int32
+	)
+	for {
+		n, err := b.ReadSamples(samples)
+		for _, v := range samples[:n] {
+			if v != next {
+				t.Fatalf("expected sample %d, got %d", next, v)
+			}
+			next++
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if next != total {
+		t.Fatalf("expected %d samples, got %d", total, next)
+	}
+}
+
+func TestBufferNonBlocking(t *testing.T) {
+	b, err := New[float32](audio.FormatOf[float32](48000, 2, binary.LittleEndian), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb := b.NonBlocking()
+
+	// 5 frames don't fit in 4 frames, the odd sample isn't a whole frame.
+	if n, err := nb.WriteSamples(make(audio.Samples[float32], 11)); err != ErrOverrun {
+		t.Fatalf("expected error %q, got %v", ErrOverrun, err)
+	} else if n != 8 {
+		t.Fatalf("expected 8 samples, got %d", n)
+	}
+	if v := b.Len(); v != 4 {
+		t.Fatalf("expected 4 frames, got %d", v)
+	}
+
+	samples := make(audio.Samples[float32], 6)
+	if n, err := nb.ReadSamples(samples); err != nil || n != 6 {
+		t.Fatalf("expected 6 samples, got %d (%v)", n, err)
+	}
+	if n, err := nb.ReadSamples(samples); err != nil || n != 2 {
+		t.Fatalf("expected 2 samples, got %d (%v)", n, err)
+	}
+	if n, err := nb.ReadSamples(samples); err != nil || n != 0 {
+		t.Fatalf("expected 0 samples, got %d (%v)", n, err)
+	}
+
+	if v := b.Stats(); v.Overruns != 1 || v.Underruns != 2 {
+		t.Fatalf("expected 1 overrun and 2 underruns, got %+v", v)
+	}
+
+	_ = b.Close()
+	if _, err := nb.ReadSamples(samples); err != io.EOF {
+		t.Fatalf("expected error %q, got %v", io.EOF, err)
+	}
+	if _, err := nb.WriteSamples(samples); err != io.ErrClosedPipe {
+		t.Fatalf("expected error %q, got %v", io.ErrClosedPipe, err)
+	}
+}
+
+func TestBufferClose(t *testing.T) {
+	b, err := New[int16](audio.FormatOf[int16](48000, 2, binary.LittleEndian), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = b.WriteSamples(audio.Samples[int16]{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Close(); err != nil {
+		t.Fatalf("closing again: %v", err)
+	}
+
+	samples := make(audio.Samples[int16], 4)
+	if n, err := b.TryReadSamples(samples); err != nil || n != 2 {
+		t.Fatalf("expected 2 samples, got %d (%v)", n, err)
+	}
+	if n, err := b.TryReadSamples(samples); err != io.EOF || n != 0 {
+		t.Fatalf("expected %v, got %d samples (%v)", io.EOF, n, err)
+	}
+	if v := b.Stats(); v.Underruns != 0 {
+		t.Errorf("expected no underruns after closing, got %d", v.Underruns)
+	}
+}
+
+func TestBufferFrames(t *testing.T) {
+	b, err := New[int16](audio.FormatOf[int16](48000, 3, binary.LittleEndian), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := audio.Buffer[int16]{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}
+	for i := 0; i < 4; i++ {
+		// Wrap around the end of the buffer a couple of times.
+		if n, err := b.WriteBuffer(in); err != nil || n != 3 {
+			t.Fatalf("expected 3 frames, got %d (%v)", n, err)
+		}
+
+		out := audio.Buffer[int16]{make([]int16, 3), make([]int16, 3), make([]int16, 3)}
+		if n, err := b.ReadBuffer(out); err != nil || n != 3 {
+			t.Fatalf("expected 3 frames, got %d (%v)", n, err)
+		}
+		for c := range in {
+			for j, v := range in[c] {
+				if out[c][j] != v {
+					t.Errorf("expected sample %d of channel %d to be %d, got %d", j, c, v, out[c][j])
+				}
+			}
+		}
+	}
+}
+
+func TestBufferChannels(t *testing.T) {
+	b, err := New[int16](audio.FormatOf[int16](48000, 2, binary.LittleEndian), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mono := audio.Buffer[int16]{make([]int16, 3)}
+	if _, err = b.WriteBuffer(mono); !errors.Is(err, ErrChannels) {
+		t.Errorf("expected %v writing mono, got %v", ErrChannels, err)
+	}
+	if _, err = b.ReadBuffer(mono); !errors.Is(err, ErrChannels) {
+		t.Errorf("expected %v reading mono, got %v", ErrChannels, err)
+	}
+}
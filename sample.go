@@ -3,6 +3,7 @@ package audio
 import (
 	"encoding/binary"
 	"math"
+	"unsafe"
 
 	"golang.org/x/exp/constraints"
 )
@@ -179,6 +180,197 @@ func (s Samples[T]) Encode(b []byte, order binary.ByteOrder) {
 	}
 }
 
+// DecodeFormat decodes values encoded in b using the specified format.
+//
+// Samples that are stored natively as T are decoded like Decode does. 24-bit samples, either packed
+// in 3 bytes or stored in the least significant bits of 32-bit words, are sign extended and scaled
+// to the full range of T.
+func (s Samples[T]) DecodeFormat(b []byte, format Format) {
+	switch {
+	case matches[T](format):
+		s.Decode(b, format.ByteOrder)
+	case format.Bits == 24 && format.BitsPerSample() == 24:
+		big := isBigEndian(format.ByteOrder)
+		for i := range s {
+			var (
+				x = b[i*3 : i*3+3]
+				v uint32
+			)
+			if big {
+				v = uint32(x[0])<<16 | uint32(x[1])<<8 | uint32(x[2])
+			} else {
+				v = uint32(x[2])<<16 | uint32(x[1])<<8 | uint32(x[0])
+			}
+			s[i] = fromInt24[T](int24(v, format.Encoding))
+		}
+	case format.Bits == 24 && format.BitsPerSample() == 32:
+		for i := range s {
+			s[i] = fromInt24[T](int24(format.ByteOrder.Uint32(b[i<<2:]), format.Encoding))
+		}
+	}
+}
+
+// EncodeFormat encodes the sample slice as bytes in b using the specified format.
+//
+// See DecodeFormat for the supported formats.
+func (s Samples[T]) EncodeFormat(b []byte, format Format) {
+	switch {
+	case matches[T](format):
+		s.Encode(b, format.ByteOrder)
+	case format.Bits == 24 && format.BitsPerSample() == 24:
+		big := isBigEndian(format.ByteOrder)
+		for i, v := range s {
+			var (
+				x = b[i*3 : i*3+3]
+				u = uint24(toInt24(v), format.Encoding)
+			)
+			if big {
+				x[0], x[1], x[2] = byte(u>>16), byte(u>>8), byte(u)
+			} else {
+				x[0], x[1], x[2] = byte(u), byte(u>>8), byte(u>>16)
+			}
+		}
+	case format.Bits == 24 && format.BitsPerSample() == 32:
+		for i, v := range s {
+			format.ByteOrder.PutUint32(b[i<<2:], uint24(toInt24(v), format.Encoding))
+		}
+	}
+}
+
+// canDecode checks if samples of type T can be decoded from (and encoded to) the format.
+func canDecode[T Sample](format Format) bool {
+	if matches[T](format) {
+		return true
+	}
+	switch format.Encoding {
+	case Signed, Unsigned:
+		return format.Bits == 24 && (format.BitsPerSample() == 24 || format.BitsPerSample() == 32)
+	default:
+		return false
+	}
+}
+
+// isBigEndian checks if order stores the most significant byte first.
+func isBigEndian(order binary.ByteOrder) bool {
+	switch order {
+	case binary.BigEndian:
+		return true
+	case binary.LittleEndian:
+		return false
+	case binary.NativeEndian:
+		return hostBigEndian
+	default:
+		return order.Uint16([]byte{0x00, 0x01}) == 0x0001
+	}
+}
+
+var hostBigEndian = binary.NativeEndian.Uint16([]byte{0x00, 0x01}) == 0x0001
+
+// Bytes returns the memory of s as a byte slice without copying, so samples are in the byte
+// order of the host (see binary.NativeEndian).
+//
+// The returned slice shares memory with s, modifying one modifies the other.
+func (s Samples[T]) Bytes() []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(s))), len(s)*int(unsafe.Sizeof(s[0])))
+}
+
+// int24 returns the signed 24-bit value stored in the 24 least significant bits of v.
+func int24(v uint32, encoding Encoding) int32 {
+	if encoding == Unsigned {
+		return int32(v&0xffffff) - 0x800000
+	}
+	return int32(v<<8) >> 8
+}
+
+// uint24 returns the 24-bit signed value v in the 24 least significant bits.
+//
+// Signed values are sign extended to 32 bits, as expected by ALSA.
+func uint24(v int32, encoding Encoding) uint32 {
+	if encoding == Unsigned {
+		return uint32(v + 0x800000)
+	}
+	return uint32(v)
+}
+
+// fromInt24 scales a signed 24-bit value to the full range of T.
+func fromInt24[T Sample](v int32) T {
+	var zero T
+	switch any(zero).(type) {
+	case int:
+		return T(int(v) << (intSize - 24))
+	case int8:
+		return T(v >> 16)
+	case int16:
+		return T(v >> 8)
+	case int32:
+		return T(v << 8)
+	case int64:
+		return T(int64(v) << 40)
+	case uint:
+		return T(uint(int(v)<<(intSize-24)) ^ (1 << (intSize - 1)))
+	case uint8:
+		return T(uint8(v>>16) ^ 0x80)
+	case uint16:
+		return T(uint16(v>>8) ^ 0x8000)
+	case uint32:
+		return T(uint32(v<<8) ^ 0x80000000)
+	case uint64:
+		return T(uint64(int64(v)<<40) ^ (1 << 63))
+	case float32:
+		return T(float32(v) / (1 << 23))
+	case float64:
+		return T(float64(v) / (1 << 23))
+	default:
+		return zero
+	}
+}
+
+// toInt24 scales v from the full range of T to a signed 24-bit value.
+func toInt24[T Sample](v T) int32 {
+	switch x := any(v).(type) {
+	case int:
+		return int32(x >> (intSize - 24))
+	case int8:
+		return int32(x) << 16
+	case int16:
+		return int32(x) << 8
+	case int32:
+		return x >> 8
+	case int64:
+		return int32(x >> 40)
+	case uint:
+		return int32(int(x^(1<<(intSize-1))) >> (intSize - 24))
+	case uint8:
+		return int32(int8(x^0x80)) << 16
+	case uint16:
+		return int32(int16(x^0x8000)) << 8
+	case uint32:
+		return int32(x^0x80000000) >> 8
+	case uint64:
+		return int32(int64(x^(1<<63)) >> 40)
+	case float32:
+		return clampInt24(math.Round(float64(x) * (1 << 23)))
+	case float64:
+		return clampInt24(math.Round(x * (1 << 23)))
+	default:
+		return 0
+	}
+}
+
+func clampInt24(v float64) int32 {
+	switch {
+	case v < -0x800000:
+		return -0x800000
+	case v > 0x7fffff:
+		return 0x7fffff
+	default:
+		return int32(v)
+	}
+}
+
 // intSize is the number of bits used by the int/uint type, dependent on CPU architecture
 const intSize = 32 << (^uint(0) >> 63)
 
@@ -784,6 +784,135 @@ func TestFloat64(t *testing.T) {
 	})
 }
 
+func TestInt24(t *testing.T) {
+	var (
+		packed = []byte{
+			0x00, 0x00, 0x00,
+			0x00, 0x00, 0x01,
+			0x40, 0x00, 0x00,
+			0x7f, 0xff, 0xff,
+			0xff, 0xff, 0xff,
+			0xc0, 0x00, 0x00,
+			0x80, 0x00, 0x00,
+		}
+		packedUnsigned = []byte{
+			0x80, 0x00, 0x00,
+			0x80, 0x00, 0x01,
+			0xc0, 0x00, 0x00,
+			0xff, 0xff, 0xff,
+			0x7f, 0xff, 0xff,
+			0x40, 0x00, 0x00,
+			0x00, 0x00, 0x00,
+		}
+		in32 = []byte{
+			0x00, 0x00, 0x00, 0x00,
+			0x01, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x40, 0x00,
+			0xff, 0xff, 0x7f, 0x00,
+			0xff, 0xff, 0xff, 0xff,
+			0x00, 0x00, 0xc0, 0xff,
+			0x00, 0x00, 0x80, 0xff,
+		}
+		wantInt32 = audio.Samples[int32]{
+			0, 0x100, 0x40000000, 0x7fffff00, -0x100, -0x40000000, math.MinInt32,
+		}
+		wantFloat64 = audio.Samples[float64]{
+			0, 1.0 / (1 << 23), 0.5, 1 - 1.0/(1<<23), -1.0 / (1 << 23), -0.5, -1,
+		}
+		s24be      = audio.Format{SampleRate: 48000, Channels: 1, Encoding: audio.Signed, Bits: 24, ByteOrder: binary.BigEndian}
+		u24be      = audio.Format{SampleRate: 48000, Channels: 1, Encoding: audio.Unsigned, Bits: 24, ByteOrder: binary.BigEndian}
+		s24in32le  = audio.Format{SampleRate: 48000, Channels: 1, Encoding: audio.Signed, Bits: 24, Container: 32, ByteOrder: binary.LittleEndian}
+		numSamples = len(wantInt32)
+	)
+	t.Run("decode int32", func(it *testing.T) {
+		test := make(audio.Samples[int32], numSamples)
+		test.DecodeFormat(packed, s24be)
+		for i, v := range test {
+			if v != wantInt32[i] {
+				it.Errorf("expected value %d to decode to %#x, got %#x", i, wantInt32[i], v)
+			}
+		}
+	})
+	t.Run("decode unsigned", func(it *testing.T) {
+		test := make(audio.Samples[int32], numSamples)
+		test.DecodeFormat(packedUnsigned, u24be)
+		for i, v := range test {
+			if v != wantInt32[i] {
+				it.Errorf("expected value %d to decode to %#x, got %#x", i, wantInt32[i], v)
+			}
+		}
+	})
+	t.Run("decode float64", func(it *testing.T) {
+		test := make(audio.Samples[float64], numSamples)
+		test.DecodeFormat(in32, s24in32le)
+		for i, v := range test {
+			if v != wantFloat64[i] {
+				it.Errorf("expected value %d to decode to %g, got %g", i, wantFloat64[i], v)
+			}
+		}
+	})
+	t.Run("decode float32", func(it *testing.T) {
+		test := make(audio.Samples[float32], numSamples)
+		test.DecodeFormat(packed, s24be)
+		for i, v := range test {
+			if v != float32(wantFloat64[i]) {
+				it.Errorf("expected value %d to decode to %g, got %g", i, wantFloat64[i], v)
+			}
+		}
+	})
+	t.Run("encode int32", func(it *testing.T) {
+		test := make([]byte, numSamples*3)
+		wantInt32.EncodeFormat(test, s24be)
+		if !bytes.Equal(test, packed) {
+			it.Fatalf("expected values to encode to %#02v, got %#02v", packed, test)
+		}
+	})
+	t.Run("encode unsigned", func(it *testing.T) {
+		test := make([]byte, numSamples*3)
+		wantInt32.EncodeFormat(test, u24be)
+		if !bytes.Equal(test, packedUnsigned) {
+			it.Fatalf("expected values to encode to %#02v, got %#02v", packedUnsigned, test)
+		}
+	})
+	t.Run("encode float64", func(it *testing.T) {
+		test := make([]byte, numSamples*4)
+		wantFloat64.EncodeFormat(test, s24in32le)
+		if !bytes.Equal(test, in32) {
+			it.Fatalf("expected values to encode to %#02v, got %#02v", in32, test)
+		}
+	})
+	t.Run("reader", func(it *testing.T) {
+		r, err := audio.NewReader[float64](bytes.NewBuffer(packed), s24be)
+		if err != nil {
+			it.Fatal(err)
+		}
+		test := make(audio.Samples[float64], numSamples)
+		if n, err := r.ReadSamples(test); err != nil {
+			it.Fatal(err)
+		} else if n != numSamples {
+			it.Fatalf("expected %d samples, got %d", numSamples, n)
+		}
+		for i, v := range test {
+			if v != wantFloat64[i] {
+				it.Errorf("expected value %d to decode to %g, got %g", i, wantFloat64[i], v)
+			}
+		}
+	})
+	t.Run("writer", func(it *testing.T) {
+		var b bytes.Buffer
+		w, err := audio.NewWriter[int32](&b, s24in32le)
+		if err != nil {
+			it.Fatal(err)
+		}
+		if _, err = w.WriteSamples(wantInt32); err != nil {
+			it.Fatal(err)
+		}
+		if !bytes.Equal(b.Bytes(), in32) {
+			it.Fatalf("expected values to encode to %#02v, got %#02v", in32, b.Bytes())
+		}
+	})
+}
+
 func TestInterleave(t *testing.T) {
 	test := [][]byte{
 		{0, 0, 0, 0},
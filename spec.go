@@ -0,0 +1,138 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseFormat parses a format specification. Two styles are supported:
+//
+//   - sample format, channels and sample rate like "s16le:2:44100", with sample formats named like
+//     FFmpeg (s16le, f32be, s24le, u8, alaw, mulaw), ALSA (S16_LE, S24_3LE, S24_LE, FLOAT_LE,
+//     MU_LAW) or Format.String (s24in32le);
+//   - sample rate, bits and channels like MPD's "44100:16:2", where bits is 8, 16, 24 (in 32-bit
+//     containers), 32 or f for 32-bit floats, in the byte order of the host.
+//
+// Sample formats without a byte order, or with "ne", are in the byte order of the host.
+func ParseFormat(spec string) (Format, error) {
+	fields := strings.Split(spec, ":")
+	if len(fields) != 3 {
+		return Format{}, fmt.Errorf("audio: format %q doesn't have 3 fields", spec)
+	}
+
+	var (
+		format Format
+		rate   string
+		err    error
+	)
+	if _, err = strconv.Atoi(fields[0]); err == nil {
+		// MPD's sample rate:bits:channels.
+		rate = fields[0]
+		format, err = parseMPDBits(fields[1])
+		if err == nil {
+			format.Channels, err = strconv.Atoi(fields[2])
+		}
+	} else {
+		rate = fields[2]
+		format, err = ParseSampleFormat(fields[0])
+		if err == nil {
+			format.Channels, err = strconv.Atoi(fields[1])
+		}
+	}
+	if err == nil {
+		format.SampleRate, err = strconv.Atoi(rate)
+	}
+	if err != nil {
+		return Format{}, fmt.Errorf("audio: invalid format %q: %w", spec, err)
+	}
+	if err = format.Validate(); err != nil {
+		return Format{}, err
+	}
+	return format, nil
+}
+
+// parseMPDBits parses the bits of an MPD format.
+func parseMPDBits(bits string) (Format, error) {
+	format := Format{Encoding: Signed, ByteOrder: binary.NativeEndian}
+	switch bits {
+	case "8":
+		format.Bits, format.ByteOrder = 8, nil
+	case "16", "32":
+		format.Bits, _ = strconv.Atoi(bits)
+	case "24":
+		format.Bits, format.Container = 24, 32
+	case "f":
+		format.Encoding, format.Bits = Float, 32
+	default:
+		return Format{}, fmt.Errorf("unsupported bits %q", bits)
+	}
+	return format, nil
+}
+
+// ParseSampleFormat parses the name of a sample format, like "s16le", and returns a Format with
+// the encoding, bits and byte order. See ParseFormat for the supported names.
+func ParseSampleFormat(name string) (Format, error) {
+	s := strings.ToLower(name)
+	switch s {
+	case "alaw", "a_law":
+		return Format{Encoding: ALaw, Bits: 8}, nil
+	case "ulaw", "mulaw", "mu_law":
+		return Format{Encoding: MuLaw, Bits: 8}, nil
+	}
+	if rest, ok := strings.CutPrefix(s, "float64"); ok {
+		s = "f64" + rest
+	} else if rest, ok := strings.CutPrefix(s, "float"); ok {
+		s = "f32" + rest
+	}
+
+	var format Format
+	switch {
+	case strings.HasSuffix(s, "le"):
+		format.ByteOrder = binary.LittleEndian
+	case strings.HasSuffix(s, "be"):
+		format.ByteOrder = binary.BigEndian
+	case strings.HasSuffix(s, "ne"):
+		format.ByteOrder = binary.NativeEndian
+	}
+	if format.ByteOrder != nil {
+		s = s[:len(s)-2]
+	} else {
+		format.ByteOrder = binary.NativeEndian
+	}
+	// ALSA names have an underscore before the byte order.
+	s, alsa := strings.CutSuffix(s, "_")
+	s, packed := strings.CutSuffix(s, "_3")
+
+	if len(s) < 2 {
+		return Format{}, fmt.Errorf("audio: unknown sample format %q", name)
+	}
+	switch s[0] {
+	case 's':
+		format.Encoding = Signed
+	case 'u':
+		format.Encoding = Unsigned
+	case 'f':
+		format.Encoding = Float
+	default:
+		return Format{}, fmt.Errorf("audio: unknown sample format %q", name)
+	}
+	bits, container, _ := strings.Cut(s[1:], "in")
+	var err error
+	if format.Bits, err = strconv.Atoi(bits); err != nil {
+		return Format{}, fmt.Errorf("audio: unknown sample format %q", name)
+	}
+	if container != "" {
+		if format.Container, err = strconv.Atoi(container); err != nil {
+			return Format{}, fmt.Errorf("audio: unknown sample format %q", name)
+		}
+	} else if alsa && !packed && format.Bits%16 != 0 && format.Bits > 8 {
+		// ALSA stores samples like S24_LE in 32 bits, unless they are packed like S24_3LE.
+		format.Container = 32
+	}
+	if format.BitsPerSample() <= 8 {
+		format.ByteOrder = nil
+	}
+	return format, nil
+}
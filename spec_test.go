@@ -0,0 +1,49 @@
+package audio_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+)
+
+func TestParseFormat(t *testing.T) {
+	testCases := []struct {
+		Spec string
+		Want audio.Format
+	}{
+		{"s16le:2:44100", audio.FormatOf[int16](44100, 2, binary.LittleEndian)},
+		{"f32be:1:48000", audio.FormatOf[float32](48000, 1, binary.BigEndian)},
+		{"u8:1:8000", audio.FormatOf[uint8](8000, 1, nil)},
+		{"s16:2:44100", audio.FormatOf[int16](44100, 2, binary.NativeEndian)},
+		{"s24le:2:96000", audio.Format{SampleRate: 96000, Channels: 2, Encoding: audio.Signed, Bits: 24, ByteOrder: binary.LittleEndian}},
+		{"S24_LE:2:96000", audio.Format{SampleRate: 96000, Channels: 2, Encoding: audio.Signed, Bits: 24, Container: 32, ByteOrder: binary.LittleEndian}},
+		{"S24_3BE:2:96000", audio.Format{SampleRate: 96000, Channels: 2, Encoding: audio.Signed, Bits: 24, ByteOrder: binary.BigEndian}},
+		{"s24in32be:2:96000", audio.Format{SampleRate: 96000, Channels: 2, Encoding: audio.Signed, Bits: 24, Container: 32, ByteOrder: binary.BigEndian}},
+		{"FLOAT64_LE:1:48000", audio.FormatOf[float64](48000, 1, binary.LittleEndian)},
+		{"mulaw:1:8000", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.MuLaw, Bits: 8}},
+		{"A_LAW:1:8000", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.ALaw, Bits: 8}},
+		{"44100:16:2", audio.FormatOf[int16](44100, 2, binary.NativeEndian)},
+		{"48000:24:2", audio.Format{SampleRate: 48000, Channels: 2, Encoding: audio.Signed, Bits: 24, Container: 32, ByteOrder: binary.NativeEndian}},
+		{"44100:f:2", audio.FormatOf[float32](44100, 2, binary.NativeEndian)},
+		{"22050:8:1", audio.FormatOf[int8](22050, 1, nil)},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Spec, func(it *testing.T) {
+			format, err := audio.ParseFormat(test.Spec)
+			if err != nil {
+				it.Fatal(err)
+			}
+			if format != test.Want {
+				it.Errorf("expected %s, got %s", test.Want, format)
+			}
+		})
+	}
+
+	for _, spec := range []string{"", "s16le:2", "x16le:2:44100", "s16le:two:44100", "44100:dsd:2", "44100:12:2", "f16le:1:8000", "s16le:0:44100"} {
+		if format, err := audio.ParseFormat(spec); err == nil {
+			t.Errorf("expected an error for %q, got %s", spec, format)
+		}
+	}
+}
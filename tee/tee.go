@@ -0,0 +1,263 @@
+// Package tee splits one stream of samples into multiple independent streams.
+package tee
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/BeatGlow/audio"
+)
+
+var ErrFrames = errors.New("tee: need room for at least one frame")
+
+// Policy decides what happens to samples for a branch that is full, because it is read slower
+// than the other branches.
+type Policy int
+
+const (
+	// Block stops reading from the source until all branches have room, so the slowest branch
+	// sets the pace.
+	Block Policy = iota
+
+	// DropOldest discards the oldest frames of a full branch to make room.
+	DropOldest
+
+	// DropNewest discards the frames that don't fit in a full branch.
+	DropNewest
+)
+
+func (p Policy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropOldest:
+		return "drop oldest"
+	case DropNewest:
+		return "drop newest"
+	default:
+		return fmt.Sprintf("unknown policy %d", p)
+	}
+}
+
+// Splitter reads from a source and copies the samples to all of its branches.
+//
+// The source is read by whichever branch needs samples first, so the source should return
+// whole frames.
+type Splitter[T audio.Sample] struct {
+	source audio.Reader[T]
+	format audio.Format
+	frames int
+	policy Policy
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	branches []*Branch[T]
+	reading  bool
+	err      error
+	scratch  audio.Samples[T]
+}
+
+// Branch is one of the streams of a Splitter, which buffers samples until they are read. Each
+// branch should be read by a single goroutine.
+type Branch[T audio.Sample] struct {
+	splitter *Splitter[T]
+
+	// data is a ring buffer of size samples starting at start, guarded by the splitter.
+	data    audio.Samples[T]
+	start   int
+	size    int
+	dropped uint64
+	closed  bool
+}
+
+// Stats are the statistics of a Branch.
+type Stats struct {
+	// Dropped is the number of frames that were discarded because the branch was full.
+	Dropped uint64
+}
+
+// New splits samples in the given format read from r, each branch buffers up to frames frames.
+func New[T audio.Sample](r audio.Reader[T], format audio.Format, frames int, policy Policy) (*Splitter[T], error) {
+	if format.Channels < 1 {
+		return nil, audio.ErrChannels
+	}
+	if frames < 1 {
+		return nil, ErrFrames
+	}
+	s := &Splitter[T]{
+		source:  r,
+		format:  format,
+		frames:  frames,
+		policy:  policy,
+		scratch: make(audio.Samples[T], frames*format.Channels),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s, nil
+}
+
+// Format of the samples.
+func (s *Splitter[T]) Format() audio.Format {
+	return s.format
+}
+
+func (s *Splitter[T]) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("tee %d branches, %s", len(s.branches), s.policy)
+}
+
+// Branch adds a branch, which receives all samples read from the source after it was added.
+func (s *Splitter[T]) Branch() *Branch[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := &Branch[T]{
+		splitter: s,
+		data:     make(audio.Samples[T], s.frames*s.format.Channels),
+	}
+	s.branches = append(s.branches, b)
+	return b
+}
+
+// room returns the number of samples that can be read from the source, which is limited by the
+// fullest branch for the Block policy.
+func (s *Splitter[T]) room(samples int) int {
+	if s.policy != Block {
+		return min(samples, len(s.scratch))
+	}
+	for _, b := range s.branches {
+		samples = min(samples, len(b.data)-b.size)
+	}
+	return samples
+}
+
+// fill reads from the source and copies the samples to all branches, with s.mu held.
+func (s *Splitter[T]) fill(samples int) {
+	s.reading = true
+	buffer := s.scratch[:s.room(samples)]
+	s.mu.Unlock()
+	n, err := s.source.ReadSamples(buffer)
+	s.mu.Lock()
+	s.reading = false
+
+	for _, b := range s.branches {
+		b.put(buffer[:n], s.policy, s.format.Channels)
+	}
+	if err != nil {
+		s.err = err
+	}
+	s.cond.Broadcast()
+}
+
+// Format of the samples.
+func (b *Branch[T]) Format() audio.Format {
+	return b.splitter.format
+}
+
+// Len is the number of buffered frames.
+func (b *Branch[T]) Len() int {
+	b.splitter.mu.Lock()
+	defer b.splitter.mu.Unlock()
+	return b.size / b.splitter.format.Channels
+}
+
+// Stats returns the dropped frames counter.
+func (b *Branch[T]) Stats() Stats {
+	b.splitter.mu.Lock()
+	defer b.splitter.mu.Unlock()
+	return Stats{Dropped: b.dropped}
+}
+
+// Close removes the branch from the splitter and discards its buffered samples, so it no longer
+// receives samples or blocks other branches.
+func (b *Branch[T]) Close() error {
+	s := b.splitter
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed, b.size = true, 0
+	for i, other := range s.branches {
+		if other == b {
+			s.branches = append(s.branches[:i], s.branches[i+1:]...)
+			break
+		}
+	}
+	s.cond.Broadcast()
+	return nil
+}
+
+// ReadSamples reads as many whole frames as fit in samples, blocking until samples are
+// available. Once the source has failed and the branch is drained, the source error is returned.
+func (b *Branch[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	s := b.splitter
+	samples = samples[:len(samples)-len(samples)%s.format.Channels]
+	if len(samples) == 0 {
+		return 0, io.ErrShortBuffer
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for b.size == 0 {
+		switch {
+		case b.closed:
+			return 0, io.ErrClosedPipe
+		case s.err != nil:
+			return 0, s.err
+		case s.reading || s.room(len(samples)) == 0:
+			// Wait for the source to be read, or for room in all branches.
+			s.cond.Wait()
+		default:
+			s.fill(len(samples))
+		}
+	}
+
+	n := b.get(samples)
+	s.cond.Broadcast()
+	return n, nil
+}
+
+// put copies samples to the ring buffer, dropping frames if it is full.
+func (b *Branch[T]) put(samples audio.Samples[T], policy Policy, channels int) {
+	if over := b.size + len(samples) - len(b.data); over > 0 {
+		switch policy {
+		case DropOldest:
+			over = min(over, b.size)
+			b.start = (b.start + over) % len(b.data)
+			b.size -= over
+			b.dropped += uint64(over / channels)
+			// Samples that still don't fit are the oldest of samples.
+			if skip := len(samples) - len(b.data); skip > 0 {
+				samples = samples[skip:]
+				b.dropped += uint64(skip / channels)
+			}
+		default:
+			samples = samples[:len(b.data)-b.size]
+			b.dropped += uint64(over / channels)
+		}
+	}
+
+	for len(samples) > 0 {
+		end, limit := (b.start+b.size)%len(b.data), len(b.data)
+		if end < b.start {
+			limit = b.start
+		}
+		n := copy(b.data[end:limit], samples)
+		b.size += n
+		samples = samples[n:]
+	}
+}
+
+// get copies samples from the ring buffer.
+func (b *Branch[T]) get(samples audio.Samples[T]) int {
+	var n int
+	for n < len(samples) && b.size > 0 {
+		m := copy(samples[n:], b.data[b.start:min(len(b.data), b.start+b.size)])
+		b.start = (b.start + m) % len(b.data)
+		b.size -= m
+		n += m
+	}
+	return n
+}
@@ -0,0 +1,150 @@
+package tee
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/BeatGlow/audio"
+)
+
+var testFormat = audio.FormatOf[int16](8000, 2, binary.LittleEndian)
+
+// testCounter returns frames counting up from 0 on both channels, until it reaches end.
+type testCounter struct {
+	next, end int16
+}
+
+func (c *testCounter) ReadSamples(samples audio.Samples[int16]) (int, error) {
+	if c.next == c.end {
+		return 0, io.EOF
+	}
+	var n int
+	for ; n+1 < len(samples) && c.next < c.end; n += 2 {
+		samples[n], samples[n+1] = c.next, c.next
+		c.next++
+	}
+	return n, nil
+}
+
+func testReadAll(t *testing.T, b *Branch[int16], size int) audio.Samples[int16] {
+	var (
+		all    audio.Samples[int16]
+		buffer = make(audio.Samples[int16], size)
+	)
+	for {
+		n, err := b.ReadSamples(buffer)
+		all = append(all, buffer[:n]...)
+		if err == io.EOF {
+			return all
+		} else if err != nil {
+			t.Error(err)
+			return all
+		}
+	}
+}
+
+func TestSplitterBlock(t *testing.T) {
+	s, err := New[int16](&testCounter{end: 1000}, testFormat, 16, Block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		wait     sync.WaitGroup
+		branches = []*Branch[int16]{s.Branch(), s.Branch(), s.Branch()}
+		results  = make([]audio.Samples[int16], len(branches))
+	)
+	for i, b := range branches {
+		wait.Add(1)
+		go func(i int, b *Branch[int16]) {
+			defer wait.Done()
+			results[i] = testReadAll(t, b, 2*(i*7+1))
+		}(i, b)
+	}
+	wait.Wait()
+
+	for i, result := range results {
+		if len(result) != 2000 {
+			t.Errorf("expected branch %d to read 2000 samples, got %d", i, len(result))
+			continue
+		}
+		for j := 0; j < len(result); j += 2 {
+			if result[j] != int16(j/2) {
+				t.Errorf("expected branch %d frame %d to be %d, got %d", i, j/2, j/2, result[j])
+				break
+			}
+		}
+		if v := branches[i].Stats().Dropped; v != 0 {
+			t.Errorf("expected branch %d to drop no frames, got %d", i, v)
+		}
+	}
+}
+
+func TestSplitterDrop(t *testing.T) {
+	testCases := []struct {
+		Policy Policy
+		First  int16
+	}{
+		{DropNewest, 0},
+		{DropOldest, 100 - 8},
+	}
+	for _, test := range testCases {
+		t.Run(test.Policy.String(), func(it *testing.T) {
+			s, err := New[int16](&testCounter{end: 100}, testFormat, 8, test.Policy)
+			if err != nil {
+				it.Fatal(err)
+			}
+			fast, slow := s.Branch(), s.Branch()
+
+			if v := testReadAll(it, fast, 6); len(v) != 200 {
+				it.Fatalf("expected the fast branch to read 200 samples, got %d", len(v))
+			}
+			if v := slow.Len(); v != 8 {
+				it.Errorf("expected the slow branch to buffer 8 frames, got %d", v)
+			}
+			if v := slow.Stats().Dropped; v != 92 {
+				it.Errorf("expected the slow branch to drop 92 frames, got %d", v)
+			}
+
+			v := testReadAll(it, slow, 32)
+			if len(v) != 16 {
+				it.Fatalf("expected the slow branch to read 16 samples, got %d", len(v))
+			}
+			for i := 0; i < len(v); i += 2 {
+				if want := test.First + int16(i/2); v[i] != want {
+					it.Errorf("expected frame %d to be %d, got %d", i/2, want, v[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitterClose(t *testing.T) {
+	s, err := New[int16](&testCounter{end: 100}, testFormat, 4, Block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, slow := s.Branch(), s.Branch()
+
+	done := make(chan audio.Samples[int16])
+	go func() {
+		done <- testReadAll(t, fast, 8)
+	}()
+
+	// The fast branch blocks until the slow branch is closed.
+	buffer := make(audio.Samples[int16], 2)
+	if _, err = slow.ReadSamples(buffer); err != nil {
+		t.Fatal(err)
+	}
+	if err = slow.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if v := <-done; len(v) != 200 {
+		t.Errorf("expected the fast branch to read 200 samples, got %d", len(v))
+	}
+	if _, err = slow.ReadSamples(buffer); err != io.ErrClosedPipe {
+		t.Errorf("expected error %q, got %q", io.ErrClosedPipe, err)
+	}
+}
@@ -0,0 +1,126 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+)
+
+// Broadcast is the bext chunk of Broadcast Wave files, as specified by EBU Tech 3285.
+type Broadcast struct {
+	Description         string // up to 256 characters
+	Originator          string // up to 32 characters
+	OriginatorReference string // up to 32 characters
+
+	// Origination is the date and time the recording was made. The time zone isn't stored, times
+	// are read as UTC.
+	Origination time.Time
+
+	// TimeReference is the first sample frame of the recording, counted from midnight.
+	TimeReference uint64
+
+	// Version of the chunk. It's written as at least 2 if any loudness field is set, since older
+	// versions reserve their bytes.
+	Version uint16
+
+	// UMID is the SMPTE unique material identifier.
+	UMID [64]byte
+
+	// Loudness fields, in hundredths of LUFS, LU or dBTP.
+	LoudnessValue        int16
+	LoudnessRange        int16
+	MaxTruePeakLevel     int16
+	MaxMomentaryLoudness int16
+	MaxShortTermLoudness int16
+
+	// CodingHistory describes the processing of the audio, one line per step.
+	CodingHistory string
+}
+
+// bextSize is the size of a bext chunk without the coding history.
+const bextSize = 602
+
+// TimeOfDay returns the time reference as the duration since midnight.
+func (bext *Broadcast) TimeOfDay(sampleRate int) time.Duration {
+	frames := int64(bext.TimeReference)
+	return time.Duration(frames/int64(sampleRate))*time.Second +
+		time.Duration(frames%int64(sampleRate))*time.Second/time.Duration(sampleRate)
+}
+
+// parseBroadcast parses a bext chunk.
+func parseBroadcast(b []byte) *Broadcast {
+	if len(b) < bextSize {
+		return nil
+	}
+	return &Broadcast{
+		Description:          text(b[:256]),
+		Originator:           text(b[256:288]),
+		OriginatorReference:  text(b[288:320]),
+		Origination:          parseOrigination(b[320:330], b[330:338]),
+		TimeReference:        binary.LittleEndian.Uint64(b[338:]),
+		Version:              binary.LittleEndian.Uint16(b[346:]),
+		UMID:                 [64]byte(b[348:412]),
+		LoudnessValue:        int16(binary.LittleEndian.Uint16(b[412:])),
+		LoudnessRange:        int16(binary.LittleEndian.Uint16(b[414:])),
+		MaxTruePeakLevel:     int16(binary.LittleEndian.Uint16(b[416:])),
+		MaxMomentaryLoudness: int16(binary.LittleEndian.Uint16(b[418:])),
+		MaxShortTermLoudness: int16(binary.LittleEndian.Uint16(b[420:])),
+		CodingHistory:        text(b[bextSize:]),
+	}
+}
+
+// parseOrigination parses the date and time, which may use any separator.
+func parseOrigination(date, clock []byte) time.Time {
+	d := []byte(string(date))
+	if len(bytes.Trim(d, "\x00 ")) == 0 {
+		return time.Time{}
+	}
+	d[4], d[7] = '-', '-'
+	c := []byte(string(clock))
+	if len(bytes.Trim(c, "\x00 ")) == 0 {
+		c = []byte("00:00:00")
+	}
+	c[2], c[5] = ':', ':'
+	t, err := time.Parse(time.DateTime, string(d)+" "+string(c))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// encode the chunk body.
+func (bext *Broadcast) encode() []byte {
+	b := make([]byte, bextSize, bextSize+len(bext.CodingHistory))
+	copy(b[:256], bext.Description)
+	copy(b[256:288], bext.Originator)
+	copy(b[288:320], bext.OriginatorReference)
+	if !bext.Origination.IsZero() {
+		copy(b[320:338], bext.Origination.Format("2006-01-0215:04:05"))
+	}
+	binary.LittleEndian.PutUint64(b[338:], bext.TimeReference)
+	binary.LittleEndian.PutUint16(b[346:], bext.version())
+	copy(b[348:412], bext.UMID[:])
+	binary.LittleEndian.PutUint16(b[412:], uint16(bext.LoudnessValue))
+	binary.LittleEndian.PutUint16(b[414:], uint16(bext.LoudnessRange))
+	binary.LittleEndian.PutUint16(b[416:], uint16(bext.MaxTruePeakLevel))
+	binary.LittleEndian.PutUint16(b[418:], uint16(bext.MaxMomentaryLoudness))
+	binary.LittleEndian.PutUint16(b[420:], uint16(bext.MaxShortTermLoudness))
+	return append(b, bext.CodingHistory...)
+}
+
+// version of the chunk to write, which is at least 2 if any loudness field is set.
+func (bext *Broadcast) version() uint16 {
+	if bext.LoudnessValue != 0 || bext.LoudnessRange != 0 || bext.MaxTruePeakLevel != 0 ||
+		bext.MaxMomentaryLoudness != 0 || bext.MaxShortTermLoudness != 0 {
+		return max(bext.Version, 2)
+	}
+	return bext.Version
+}
+
+// text returns a NUL padded string.
+func text(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
@@ -0,0 +1,355 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/codec/adpcm"
+	"github.com/BeatGlow/audio/internal/chunk"
+	"github.com/BeatGlow/audio/internal/pcm"
+)
+
+// Reader reads samples from a WAVE file.
+type Reader[T audio.Sample] struct {
+	// Metadata of the file. Chunks after the samples are only parsed if the io.Reader can seek.
+	Metadata
+
+	format    audio.Format
+	tag       FormatTag
+	validBits int
+	layout    audio.Layout
+	frames    int64
+	reader    audio.Reader[T]
+
+	// remaining samples of compressed formats, or -1 if not limited.
+	remaining int64
+}
+
+// header is the parsed fmt chunk.
+type header struct {
+	tag          FormatTag
+	channels     int
+	sampleRate   int
+	blockAlign   int
+	bits         int
+	validBits    int
+	channelMask  uint32
+	coefficients [][2]int16
+	hasMask      bool
+}
+
+// NewReader parses the header of a WAVE file read from r, and returns a Reader for its samples
+// converted to T.
+//
+// If r is an io.ReadSeeker and an io.ReaderAt, like an *os.File, chunks after the samples are
+// parsed as well and the Reader can seek to a frame.
+func NewReader[T audio.Sample](r io.Reader) (*Reader[T], error) {
+	seeker, readerAt, start, err := chunk.Start(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrNotWAV
+		}
+		return nil, err
+	}
+	switch string(riff[:4]) {
+	case "RIFF", "RF64", "BW64":
+	default:
+		return nil, ErrNotWAV
+	}
+	if string(riff[8:]) != "WAVE" {
+		return nil, ErrNotWAV
+	}
+
+	var (
+		rd         = &Reader[T]{remaining: -1}
+		h          *header
+		offset     = start + int64(len(riff))
+		dataOffset = int64(-1)
+		dataSize   int64
+		factFrames = int64(-1)
+		sizes      *ds64
+	)
+chunks:
+	for {
+		id, size, err := chunk.ReadHeader(r, binary.LittleEndian)
+		if err == io.EOF && dataOffset >= 0 {
+			break
+		} else if err == io.EOF {
+			return nil, ErrData
+		} else if err != nil {
+			return nil, err
+		}
+		offset += 8
+		if size == sizeRF64 && sizes != nil {
+			// The size of the chunk is in the ds64 chunk.
+			size = sizes.size(id)
+		}
+
+		if id == "data" {
+			dataOffset, dataSize = offset, size
+			if seeker == nil {
+				break chunks
+			}
+			// Continue with the chunks after the samples.
+			if _, err := seeker.Seek(chunk.Padded(size), io.SeekCurrent); err != nil {
+				return nil, err
+			}
+			offset += chunk.Padded(size)
+			continue
+		}
+
+		switch id {
+		case "fmt ", "LIST", "cue ", "smpl", "fact", "bext", "ds64":
+			body, err := chunk.ReadBody(r, id, size)
+			if errors.Is(err, chunk.ErrTooLarge) {
+				return nil, fmt.Errorf("wav: %w", err)
+			} else if err != nil {
+				if dataOffset >= 0 {
+					// Truncated metadata after the samples.
+					break chunks
+				}
+				return nil, err
+			}
+
+			switch id {
+			case "fmt ":
+				if h, err = parseHeader(body); err != nil {
+					return nil, err
+				}
+			case "LIST":
+				if len(body) >= 4 && string(body[:4]) == "INFO" {
+					rd.Info = parseInfo(body[4:])
+				}
+			case "cue ":
+				rd.Cues = parseCues(body)
+			case "smpl":
+				rd.Sampler = parseSampler(body)
+			case "fact":
+				if len(body) >= 4 {
+					factFrames = int64(binary.LittleEndian.Uint32(body))
+				}
+				if factFrames == sizeRF64 && sizes != nil {
+					factFrames = sizes.frames
+				}
+			case "bext":
+				rd.Broadcast = parseBroadcast(body)
+			case "ds64":
+				if sizes = parseDS64(body); sizes == nil {
+					return nil, fmt.Errorf("wav: invalid ds64 chunk")
+				}
+			}
+		default:
+			if err := chunk.Skip(r, chunk.Padded(size)); err != nil {
+				if dataOffset >= 0 {
+					break chunks
+				}
+				return nil, err
+			}
+		}
+		offset += chunk.Padded(size)
+	}
+
+	if h == nil {
+		return nil, ErrFormat
+	}
+
+	var data io.Reader
+	if readerAt != nil {
+		data = io.NewSectionReader(readerAt, dataOffset, dataSize)
+	} else {
+		data = io.LimitReader(r, dataSize)
+	}
+	if err := rd.init(h, data, dataSize, factFrames); err != nil {
+		return nil, err
+	}
+	return rd, nil
+}
+
+// parseHeader parses a fmt chunk.
+func parseHeader(b []byte) (*header, error) {
+	if len(b) < 16 {
+		return nil, ErrFormat
+	}
+	h := &header{
+		tag:        FormatTag(binary.LittleEndian.Uint16(b)),
+		channels:   int(binary.LittleEndian.Uint16(b[2:])),
+		sampleRate: int(binary.LittleEndian.Uint32(b[4:])),
+		blockAlign: int(binary.LittleEndian.Uint16(b[12:])),
+		bits:       int(binary.LittleEndian.Uint16(b[14:])),
+	}
+	h.validBits = h.bits
+	if h.channels < 1 || h.blockAlign < 1 {
+		return nil, ErrFormat
+	}
+
+	var ext []byte
+	if len(b) >= 18 {
+		size := int(binary.LittleEndian.Uint16(b[16:]))
+		ext = b[18:min(len(b), 18+size)]
+	}
+
+	switch h.tag {
+	case FormatExtensible:
+		if len(ext) < 22 || [14]byte(ext[8:22]) != subFormatSuffix {
+			return nil, ErrFormat
+		}
+		h.hasMask = true
+		if v := int(binary.LittleEndian.Uint16(ext)); v > 0 {
+			h.validBits = v
+		}
+		h.channelMask = binary.LittleEndian.Uint32(ext[2:])
+		h.tag = FormatTag(binary.LittleEndian.Uint16(ext[6:]))
+	case FormatADPCM:
+		// Samples per block and the coefficients.
+		if len(ext) >= 4 {
+			count := int(binary.LittleEndian.Uint16(ext[2:]))
+			for i := 0; i < count && len(ext) >= 8+i*4; i++ {
+				h.coefficients = append(h.coefficients, [2]int16{
+					int16(binary.LittleEndian.Uint16(ext[4+i*4:])),
+					int16(binary.LittleEndian.Uint16(ext[6+i*4:])),
+				})
+			}
+		}
+	}
+	return h, nil
+}
+
+// format returns the audio format of the samples, which is 16-bit PCM for ADPCM.
+func (h *header) format() (audio.Format, error) {
+	format := audio.Format{
+		SampleRate: h.sampleRate,
+		Channels:   h.channels,
+		Bits:       h.blockAlign * 8 / h.channels,
+		ByteOrder:  binary.LittleEndian,
+	}
+	switch h.tag {
+	case FormatPCM:
+		// Valid bits are stored in the most significant bits, so samples are read at the size of
+		// their container.
+		format.Encoding = audio.Signed
+		if format.Bits <= 8 {
+			format.Encoding = audio.Unsigned
+		}
+	case FormatIEEEFloat:
+		format.Encoding = audio.Float
+	case FormatALaw:
+		format.Encoding, format.ByteOrder = audio.ALaw, nil
+	case FormatMuLaw:
+		format.Encoding, format.ByteOrder = audio.MuLaw, nil
+	case FormatADPCM, FormatIMAADPCM:
+		format.Encoding, format.Bits = audio.Signed, 16
+	default:
+		return format, fmt.Errorf("wav: unsupported %s", h.tag)
+	}
+	if format.Bits <= 8 {
+		format.ByteOrder = nil
+	}
+	return format, format.Validate()
+}
+
+func (rd *Reader[T]) init(h *header, data io.Reader, size, factFrames int64) error {
+	format, err := h.format()
+	if err != nil {
+		return err
+	}
+	rd.format = format
+	rd.tag = h.tag
+	rd.validBits = h.validBits
+	rd.layout = audio.DefaultLayout(h.channels)
+	if h.hasMask {
+		rd.layout = layoutOf(h.channelMask, h.channels)
+	}
+
+	switch h.tag {
+	case FormatADPCM, FormatIMAADPCM:
+		var codec adpcm.Codec
+		if h.tag == FormatADPCM {
+			codec, err = adpcm.NewMS(h.channels, h.blockAlign, h.coefficients)
+		} else {
+			codec, err = adpcm.NewIMA(h.channels, h.blockAlign)
+		}
+		if err != nil {
+			return err
+		}
+		rd.reader = pcm.Convert[int16, T](adpcm.NewReader(data, codec), format)
+
+		blocks := (size + int64(h.blockAlign) - 1) / int64(h.blockAlign)
+		rd.frames = blocks * int64(codec.FramesPerBlock())
+		if factFrames >= 0 {
+			rd.frames = min(rd.frames, factFrames)
+			rd.remaining = rd.frames * int64(h.channels)
+		}
+	default:
+		if rd.reader, err = pcm.NewReader[T](data, format); err != nil {
+			return err
+		}
+		rd.frames = size / int64(h.blockAlign)
+	}
+	return nil
+}
+
+// Format of the samples in the file.
+func (rd *Reader[T]) Format() audio.Format {
+	return rd.format
+}
+
+// FormatTag of the samples, the format tag of the sub format for extensible formats.
+func (rd *Reader[T]) FormatTag() FormatTag {
+	return rd.tag
+}
+
+// ValidBits is the number of significant bits of the samples, which can be less than the bits
+// of the format.
+func (rd *Reader[T]) ValidBits() int {
+	return rd.validBits
+}
+
+// Layout of the channels, from the channel mask of extensible formats.
+func (rd *Reader[T]) Layout() audio.Layout {
+	return rd.layout
+}
+
+// Frames is the number of frames in the file.
+func (rd *Reader[T]) Frames() int64 {
+	return rd.frames
+}
+
+// Duration of the samples in the file.
+func (rd *Reader[T]) Duration() time.Duration {
+	return rd.format.Duration(rd.frames)
+}
+
+func (rd *Reader[T]) String() string {
+	return fmt.Sprintf("wav %s, %s", rd.tag, rd.format)
+}
+
+// ReadSamples reads samples converted to T.
+func (rd *Reader[T]) ReadSamples(samples audio.Samples[T]) (int, error) {
+	if rd.remaining == 0 {
+		return 0, io.EOF
+	} else if rd.remaining > 0 && int64(len(samples)) > rd.remaining {
+		samples = samples[:rd.remaining]
+	}
+	n, err := rd.reader.ReadSamples(samples)
+	if rd.remaining > 0 {
+		rd.remaining -= int64(n)
+	}
+	return n, err
+}
+
+// SeekFrame seeks to a frame of uncompressed samples, if the io.Reader can seek.
+func (rd *Reader[T]) SeekFrame(frame int64, whence int) (int64, error) {
+	if s, ok := rd.reader.(audio.FrameSeeker); ok {
+		return s.SeekFrame(frame, whence)
+	}
+	return 0, audio.ErrSeek
+}
@@ -0,0 +1,60 @@
+package wav
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// sizeRF64 is the size of RF64 chunks with the actual size in the ds64 chunk.
+const sizeRF64 = math.MaxUint32
+
+// maxRIFFSize is the largest size of a RIFF file, larger files are written as RF64.
+var maxRIFFSize int64 = math.MaxUint32
+
+// ds64Size is the size of a ds64 chunk without a table.
+const ds64Size = 28
+
+// ds64 is the chunk of RF64 files with the 64-bit sizes.
+type ds64 struct {
+	riff, data, frames int64
+	table              map[string]int64
+}
+
+// parseDS64 parses a ds64 chunk.
+func parseDS64(b []byte) *ds64 {
+	if len(b) < ds64Size {
+		return nil
+	}
+	sizes := &ds64{
+		riff:   int64(binary.LittleEndian.Uint64(b)),
+		data:   int64(binary.LittleEndian.Uint64(b[8:])),
+		frames: int64(binary.LittleEndian.Uint64(b[16:])),
+		table:  make(map[string]int64),
+	}
+	count := int(binary.LittleEndian.Uint32(b[24:]))
+	b = b[ds64Size:]
+	for i := 0; i < count && len(b) >= 12; i++ {
+		sizes.table[string(b[:4])] = int64(binary.LittleEndian.Uint64(b[4:]))
+		b = b[12:]
+	}
+	return sizes
+}
+
+// size returns the size of a chunk.
+func (sizes *ds64) size(id string) int64 {
+	if id == "data" {
+		return sizes.data
+	}
+	if size, ok := sizes.table[id]; ok {
+		return size
+	}
+	return sizeRF64
+}
+
+// encode the chunk body, without a table.
+func (sizes *ds64) encode() []byte {
+	b := binary.LittleEndian.AppendUint64(nil, uint64(sizes.riff))
+	b = binary.LittleEndian.AppendUint64(b, uint64(sizes.data))
+	b = binary.LittleEndian.AppendUint64(b, uint64(sizes.frames))
+	return binary.LittleEndian.AppendUint32(b, 0)
+}
@@ -0,0 +1,259 @@
+// Package wav reads and writes RIFF WAVE files, and their RF64 and BW64 variants for files larger
+// than 4 GiB.
+//
+// Supported encodings are PCM, IEEE float, A-law and µ-law, IMA and Microsoft ADPCM (reading
+// only), in plain or extensible format chunks.
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/chunk"
+)
+
+var (
+	ErrNotWAV = errors.New("wav: not a RIFF, RF64 or BW64 WAVE file")
+	ErrFormat = errors.New("wav: missing or invalid fmt chunk")
+	ErrData   = errors.New("wav: missing data chunk")
+	ErrClosed = errors.New("wav: writer is closed")
+)
+
+// FormatTag is the wFormatTag of a fmt chunk.
+type FormatTag uint16
+
+// Format tags.
+const (
+	FormatPCM        FormatTag = 0x0001
+	FormatADPCM      FormatTag = 0x0002 // Microsoft ADPCM
+	FormatIEEEFloat  FormatTag = 0x0003
+	FormatALaw       FormatTag = 0x0006
+	FormatMuLaw      FormatTag = 0x0007
+	FormatIMAADPCM   FormatTag = 0x0011
+	FormatExtensible FormatTag = 0xfffe
+)
+
+func (t FormatTag) String() string {
+	switch t {
+	case FormatPCM:
+		return "PCM"
+	case FormatADPCM:
+		return "MS ADPCM"
+	case FormatIEEEFloat:
+		return "IEEE float"
+	case FormatALaw:
+		return "A-law"
+	case FormatMuLaw:
+		return "µ-law"
+	case FormatIMAADPCM:
+		return "IMA ADPCM"
+	case FormatExtensible:
+		return "extensible"
+	default:
+		return fmt.Sprintf("format tag %#04x", uint16(t))
+	}
+}
+
+// subFormatSuffix is the GUID of an extensible format after the format tag in the first 2 bytes.
+var subFormatSuffix = [14]byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xaa, 0x00, 0x38, 0x9b, 0x71}
+
+// Metadata are the optional chunks of a WAVE file.
+type Metadata struct {
+	// Info are the text fields of the LIST INFO chunk by their ID, like INAM for the title, IART
+	// for the artist and ICMT for a comment.
+	Info map[string]string
+
+	// Cues are the points of the cue chunk.
+	Cues []Cue
+
+	// Sampler is the smpl chunk, or nil.
+	Sampler *Sampler
+
+	// Broadcast is the bext chunk of Broadcast Wave files, or nil.
+	Broadcast *Broadcast
+}
+
+// Cue is a marked position in the samples.
+type Cue struct {
+	// ID identifies the cue, for example in a Loop.
+	ID uint32
+
+	// Position is the sample frame of the cue.
+	Position uint32
+}
+
+// Sampler contains the information for samplers in the smpl chunk.
+type Sampler struct {
+	Manufacturer      uint32
+	Product           uint32
+	SamplePeriod      uint32 // in nanoseconds
+	MIDIUnityNote     uint32
+	MIDIPitchFraction uint32
+	SMPTEFormat       uint32
+	SMPTEOffset       uint32
+	Loops             []Loop
+}
+
+// LoopType is how a loop is played.
+type LoopType uint32
+
+const (
+	LoopForward LoopType = iota
+	LoopAlternating
+	LoopBackward
+)
+
+// Loop is a section of the samples that is played repeatedly.
+type Loop struct {
+	CuePointID uint32
+	Type       LoopType
+	Start      uint32 // first frame of the loop
+	End        uint32 // last frame of the loop
+	Fraction   uint32
+	PlayCount  uint32 // 0 is infinite
+}
+
+// parseInfo parses the sub chunks of a LIST INFO chunk.
+func parseInfo(b []byte) map[string]string {
+	info := make(map[string]string)
+	for len(b) >= 8 {
+		var (
+			id   = string(b[:4])
+			size = int64(binary.LittleEndian.Uint32(b[4:]))
+		)
+		b = b[8:]
+		if size > int64(len(b)) {
+			size = int64(len(b))
+		}
+		info[id] = strings.TrimRight(string(b[:size]), "\x00")
+		b = b[min(int64(len(b)), chunk.Padded(size)):]
+	}
+	return info
+}
+
+// parseCues parses a cue chunk.
+func parseCues(b []byte) []Cue {
+	if len(b) < 4 {
+		return nil
+	}
+	count := int(binary.LittleEndian.Uint32(b))
+	b = b[4:]
+	cues := make([]Cue, 0, min(count, len(b)/24))
+	for i := 0; i < count && len(b) >= 24; i++ {
+		cues = append(cues, Cue{
+			ID:       binary.LittleEndian.Uint32(b),
+			Position: binary.LittleEndian.Uint32(b[20:]),
+		})
+		b = b[24:]
+	}
+	return cues
+}
+
+// parseSampler parses a smpl chunk.
+func parseSampler(b []byte) *Sampler {
+	if len(b) < 36 {
+		return nil
+	}
+	u := func(i int) uint32 { return binary.LittleEndian.Uint32(b[i*4:]) }
+	s := &Sampler{
+		Manufacturer:      u(0),
+		Product:           u(1),
+		SamplePeriod:      u(2),
+		MIDIUnityNote:     u(3),
+		MIDIPitchFraction: u(4),
+		SMPTEFormat:       u(5),
+		SMPTEOffset:       u(6),
+	}
+	count := int(u(7))
+	b = b[36:]
+	for i := 0; i < count && len(b) >= 24; i++ {
+		u := func(i int) uint32 { return binary.LittleEndian.Uint32(b[i*4:]) }
+		s.Loops = append(s.Loops, Loop{
+			CuePointID: u(0),
+			Type:       LoopType(u(1)),
+			Start:      u(2),
+			End:        u(3),
+			Fraction:   u(4),
+			PlayCount:  u(5),
+		})
+		b = b[24:]
+	}
+	return s
+}
+
+// encode the metadata as chunks.
+func (m Metadata) encode(w io.Writer) error {
+	if m.Broadcast != nil {
+		if err := chunk.Write(w, binary.LittleEndian, "bext", m.Broadcast.encode()); err != nil {
+			return err
+		}
+	}
+
+	if len(m.Info) > 0 {
+		body := []byte("INFO")
+		for _, id := range slices.Sorted(maps.Keys(m.Info)) {
+			var (
+				value = append([]byte(m.Info[id]), 0)
+				sub   = make([]byte, 8)
+			)
+			copy(sub, (id + "    ")[:4])
+			binary.LittleEndian.PutUint32(sub[4:], uint32(len(value)))
+			body = append(append(body, sub...), value...)
+			if len(value)&1 == 1 {
+				body = append(body, 0)
+			}
+		}
+		if err := chunk.Write(w, binary.LittleEndian, "LIST", body); err != nil {
+			return err
+		}
+	}
+
+	if len(m.Cues) > 0 {
+		body := binary.LittleEndian.AppendUint32(nil, uint32(len(m.Cues)))
+		for _, cue := range m.Cues {
+			body = binary.LittleEndian.AppendUint32(body, cue.ID)
+			body = binary.LittleEndian.AppendUint32(body, cue.Position)
+			body = append(body, "data"...)
+			body = binary.LittleEndian.AppendUint32(body, 0) // chunk start
+			body = binary.LittleEndian.AppendUint32(body, 0) // block start
+			body = binary.LittleEndian.AppendUint32(body, cue.Position)
+		}
+		if err := chunk.Write(w, binary.LittleEndian, "cue ", body); err != nil {
+			return err
+		}
+	}
+
+	if s := m.Sampler; s != nil {
+		var body []byte
+		for _, v := range []uint32{
+			s.Manufacturer, s.Product, s.SamplePeriod, s.MIDIUnityNote, s.MIDIPitchFraction,
+			s.SMPTEFormat, s.SMPTEOffset, uint32(len(s.Loops)), 0,
+		} {
+			body = binary.LittleEndian.AppendUint32(body, v)
+		}
+		for _, l := range s.Loops {
+			for _, v := range []uint32{l.CuePointID, uint32(l.Type), l.Start, l.End, l.Fraction, l.PlayCount} {
+				body = binary.LittleEndian.AppendUint32(body, v)
+			}
+		}
+		if err := chunk.Write(w, binary.LittleEndian, "smpl", body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// layoutOf returns the layout for a channel mask, or the default layout if the mask doesn't match
+// the number of channels.
+func layoutOf(mask uint32, channels int) audio.Layout {
+	if layout := audio.LayoutOfMask(mask); len(layout) == channels {
+		return layout
+	}
+	return audio.DefaultLayout(channels)
+}
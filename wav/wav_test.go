@@ -0,0 +1,296 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/audiotest"
+)
+
+// write writes samples to a file in the format, with the layout and metadata.
+func write[T audio.Sample](t *testing.T, format audio.Format, layout audio.Layout, metadata Metadata, samples audio.Samples[T]) []byte {
+	t.Helper()
+	return audiotest.Write(t, func(f *audiotest.File) (*Writer[T], error) {
+		w, err := NewWriterLayout[T](f, format, layout)
+		if err == nil {
+			w.Metadata = metadata
+		}
+		return w, err
+	}, samples, ErrClosed)
+}
+
+func TestRoundTrip(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Format audio.Format
+		Layout audio.Layout
+		Tag    FormatTag
+		Header FormatTag
+	}{
+		{"u8", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.Unsigned, Bits: 8}, nil, FormatPCM, FormatPCM},
+		{"s16", audio.Format{SampleRate: 44100, Channels: 2, Encoding: audio.Signed, Bits: 16, ByteOrder: binary.LittleEndian}, nil, FormatPCM, FormatPCM},
+		{"s24", audio.Format{SampleRate: 48000, Channels: 2, Encoding: audio.Signed, Bits: 24, ByteOrder: binary.LittleEndian}, nil, FormatPCM, FormatExtensible},
+		{"f32", audio.Format{SampleRate: 48000, Channels: 1, Encoding: audio.Float, Bits: 32, ByteOrder: binary.LittleEndian}, nil, FormatIEEEFloat, FormatExtensible},
+		{"alaw", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.ALaw, Bits: 8}, nil, FormatALaw, FormatALaw},
+		{"ulaw", audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.MuLaw, Bits: 8}, nil, FormatMuLaw, FormatMuLaw},
+		{"layout", audio.Format{SampleRate: 48000, Channels: 2, Encoding: audio.Signed, Bits: 16, ByteOrder: binary.LittleEndian},
+			audio.Layout{audio.FrontCenter, audio.LowFrequency}, FormatPCM, FormatExtensible},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			samples := audiotest.Ramp(test.Format.Channels, 7)
+			data := write(it, test.Format, test.Layout, Metadata{}, samples)
+			if tag := FormatTag(binary.LittleEndian.Uint16(data[56:])); tag != test.Header {
+				it.Errorf("expected %s fmt chunk, got %s", test.Header, tag)
+			}
+			if size := int(binary.LittleEndian.Uint32(data[4:])); size != len(data)-8 {
+				it.Errorf("expected RIFF size %d, got %d", len(data)-8, size)
+			}
+
+			r, err := NewReader[int16](bytes.NewReader(data))
+			if err != nil {
+				it.Fatal(err)
+			}
+			if r.FormatTag() != test.Tag {
+				it.Errorf("expected %s, got %s", test.Tag, r.FormatTag())
+			}
+			if format := r.Format(); format.SampleRate != test.Format.SampleRate || format.Channels != test.Format.Channels || format.Encoding != test.Format.Encoding {
+				it.Errorf("expected format %s, got %s", test.Format, format)
+			}
+			if r.Frames() != 7 {
+				it.Errorf("expected 7 frames, got %d", r.Frames())
+			}
+			if test.Layout != nil && !r.Layout().Equal(test.Layout) {
+				it.Errorf("expected layout %s, got %s", test.Layout, r.Layout())
+			}
+
+			audiotest.Near(it, audiotest.ReadAll(it, r), samples)
+		})
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	format := audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.Unsigned, Bits: 8}
+	metadata := Metadata{
+		Info: map[string]string{"INAM": "Title", "IART": "Artist"},
+		Cues: []Cue{{ID: 1, Position: 2}, {ID: 2, Position: 4}},
+		Sampler: &Sampler{
+			MIDIUnityNote: 60,
+			SamplePeriod:  125000,
+			Loops:         []Loop{{CuePointID: 1, Type: LoopAlternating, Start: 2, End: 4}},
+		},
+		Broadcast: &Broadcast{
+			Description:   "Installation",
+			Originator:    "Recorder",
+			Origination:   time.Date(2024, 5, 17, 13, 45, 10, 0, time.UTC),
+			TimeReference: 8000 * 3600,
+			Version:       2,
+			LoudnessValue: -2300,
+			CodingHistory: "A=PCM,F=8000,W=8,M=mono\r\n",
+		},
+	}
+	// An odd number of samples needs a pad byte before the metadata.
+	data := write(t, format, nil, metadata, make(audio.Samples[uint8], 5))
+
+	r, err := NewReader[uint8](bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Metadata, metadata) {
+		t.Errorf("expected %+v, got %+v", metadata, r.Metadata)
+	}
+	if n := len(audiotest.ReadAll(t, r)); n != 5 {
+		t.Errorf("expected 5 samples, got %d", n)
+	}
+
+	// Without seeking, the metadata after the samples isn't read.
+	r, err = NewReader[uint8](io.MultiReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Info != nil || r.Cues != nil || r.Sampler != nil || r.Broadcast != nil {
+		t.Errorf("expected no metadata, got %+v", r.Metadata)
+	}
+	if n := len(audiotest.ReadAll(t, r)); n != 5 {
+		t.Errorf("expected 5 samples, got %d", n)
+	}
+}
+
+func TestReader(t *testing.T) {
+	// A hand-made file with an unknown chunk and 24-bit samples in 32-bit containers.
+	var b []byte
+	chunk := func(id string, body []byte) {
+		b = append(b, id...)
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(body)))
+		b = append(b, body...)
+		if len(body)&1 == 1 {
+			b = append(b, 0)
+		}
+	}
+	fmtChunk := []byte{
+		0xfe, 0xff, 1, 0, 0x44, 0xac, 0, 0, 0x10, 0xb1, 2, 0, 4, 0, 32, 0,
+		22, 0, 24, 0, 4, 0, 0, 0, 1, 0,
+	}
+	fmtChunk = append(fmtChunk, subFormatSuffix[:]...)
+	chunk("fmt ", fmtChunk)
+	chunk("junk", []byte{1, 2, 3})
+	chunk("data", []byte{0, 0, 0, 0x40, 0, 0, 0, 0xc0})
+
+	data := append([]byte("RIFF\x00\x00\x00\x00WAVE"), b...)
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+
+	r, err := NewReader[int16](bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ValidBits() != 24 {
+		t.Errorf("expected 24 valid bits, got %d", r.ValidBits())
+	}
+	if layout := (audio.Layout{audio.FrontCenter}); !r.Layout().Equal(layout) {
+		t.Errorf("expected layout %s, got %s", layout, r.Layout())
+	}
+	if got, expected := r.String(), "wav PCM, "+r.Format().String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if got := audiotest.ReadAll(t, r); !reflect.DeepEqual(got, audio.Samples[int16]{0x4000, -0x4000}) {
+		t.Errorf("unexpected samples %v", got)
+	}
+
+	// The file can start after the beginning of the io.Reader.
+	embedded := bytes.NewReader(append([]byte("junk"), data...))
+	if _, err = embedded.Seek(4, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if r, err = NewReader[int16](embedded); err != nil {
+		t.Fatal(err)
+	}
+	if got := audiotest.ReadAll(t, r); !reflect.DeepEqual(got, audio.Samples[int16]{0x4000, -0x4000}) {
+		t.Errorf("unexpected samples in an embedded file %v", got)
+	}
+
+	testCases := []struct {
+		Name string
+		Data []byte
+		Err  error
+	}{
+		{"empty", nil, ErrNotWAV},
+		{"not wave", []byte("RIFF\x04\x00\x00\x00AVI "), ErrNotWAV},
+		{"no data", data[:12+8+len(fmtChunk)], ErrData},
+		{"no fmt", append([]byte("RIFF\x0c\x00\x00\x00WAVE"), "data\x00\x00\x00\x00"...), ErrFormat},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			if _, err := NewReader[int16](bytes.NewReader(test.Data)); err != test.Err {
+				it.Errorf("expected %v, got %v", test.Err, err)
+			}
+		})
+	}
+}
+
+func TestSeek(t *testing.T) {
+	format := audio.Format{SampleRate: 1000, Channels: 2, Encoding: audio.Signed, Bits: 16, ByteOrder: binary.LittleEndian}
+	samples := make(audio.Samples[int16], 20)
+	for i := range samples {
+		samples[i] = int16(i)
+	}
+	data := write(t, format, nil, Metadata{}, samples)
+
+	r, err := NewReader[int16](bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	position, err := audio.NewPosition[int16](r, r.Format())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = position.SeekFrame(6, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	frame := make(audio.Samples[int16], 2)
+	if _, err = position.ReadSamples(frame); err != nil {
+		t.Fatal(err)
+	}
+	if frame[0] != 12 || frame[1] != 13 {
+		t.Errorf("expected frame 6, got %v", frame)
+	}
+}
+
+func TestRF64(t *testing.T) {
+	defer func(size int64) { maxRIFFSize = size }(maxRIFFSize)
+	maxRIFFSize = 100
+
+	format := audio.Format{SampleRate: 8000, Channels: 1, Encoding: audio.MuLaw, Bits: 8}
+	samples := make(audio.Samples[int16], 200)
+	for i := range samples {
+		samples[i] = int16(i * 100)
+	}
+	f := new(audiotest.File)
+	w, err := NewWriter[int16](f, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.BW64 = true
+	if _, err = w.WriteSamples(samples); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if magic := string(f.Data[:4]); magic != "BW64" {
+		t.Errorf("expected BW64, got %q", magic)
+	}
+	if id := string(f.Data[12:16]); id != "ds64" {
+		t.Errorf("expected ds64 chunk, got %q", id)
+	}
+	r, err := NewReader[int16](bytes.NewReader(f.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Frames() != 200 {
+		t.Errorf("expected 200 frames, got %d", r.Frames())
+	}
+	if n := len(audiotest.ReadAll(t, r)); n != 200 {
+		t.Errorf("expected 200 samples, got %d", n)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	b := &Broadcast{TimeReference: 48000*3600 + 24000}
+	if d := b.TimeOfDay(48000); d != time.Hour+500*time.Millisecond {
+		t.Errorf("expected 1h0m0.5s, got %s", d)
+	}
+
+	// Any separator is allowed in the date and time.
+	body := make([]byte, bextSize)
+	copy(body[320:], "2024_05_1713.45.10")
+	if got, expected := parseBroadcast(body).Origination, time.Date(2024, 5, 17, 13, 45, 10, 0, time.UTC); !got.Equal(expected) {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+	if parseBroadcast(body[:bextSize-1]) != nil {
+		t.Error("expected nil for a short chunk")
+	}
+
+	testCases := []struct {
+		Name      string
+		Broadcast Broadcast
+		Version   uint16
+	}{
+		{"no loudness", Broadcast{}, 0},
+		{"umid", Broadcast{Version: 1}, 1},
+		{"loudness", Broadcast{MaxTruePeakLevel: -100}, 2},
+		{"newer", Broadcast{Version: 3, LoudnessRange: 500}, 3},
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(it *testing.T) {
+			if v := parseBroadcast(test.Broadcast.encode()).Version; v != test.Version {
+				it.Errorf("expected version %d, got %d", test.Version, v)
+			}
+		})
+	}
+}
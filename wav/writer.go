@@ -0,0 +1,276 @@
+package wav
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/BeatGlow/audio"
+	"github.com/BeatGlow/audio/internal/pcm"
+)
+
+// Writer writes samples to a WAVE file.
+//
+// The header is written with empty sizes, which are patched when the Writer is closed. Files that
+// grow beyond 4 GiB are converted to RF64, using the space of a JUNK chunk for the ds64 chunk.
+type Writer[T audio.Sample] struct {
+	// Metadata is written after the samples when the Writer is closed. This includes the bext
+	// chunk, which Readers that can't seek don't see.
+	Metadata
+
+	// BW64 converts large files to BW64 instead of RF64.
+	BW64 bool
+
+	w      io.WriteSeeker
+	format audio.Format
+	layout audio.Layout
+	writer audio.Writer[T]
+
+	// start is the offset of the RIFF header, dataOffset the offset of the samples.
+	start      int64
+	dataOffset int64
+	size       int64
+	closed     bool
+}
+
+// NewWriter writes a header for samples in the given format to w, and returns a Writer for
+// samples of type T. The format may be PCM, float, A-law or µ-law.
+//
+// An extensible format chunk is written for more than 2 channels or more than 16 bits, with the
+// default channel layout.
+func NewWriter[T audio.Sample](w io.WriteSeeker, format audio.Format) (*Writer[T], error) {
+	return NewWriterLayout[T](w, format, audio.DefaultLayout(format.Channels))
+}
+
+// NewWriterLayout is like NewWriter, with the channel layout for the channel mask. A layout that
+// doesn't match the default layout selects an extensible format chunk.
+func NewWriterLayout[T audio.Sample](w io.WriteSeeker, format audio.Format, layout audio.Layout) (*Writer[T], error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	if len(layout) != 0 && len(layout) != format.Channels {
+		return nil, fmt.Errorf("wav: layout %s doesn't match %d channels", layout, format.Channels)
+	}
+	switch format.Encoding {
+	case audio.Signed, audio.Unsigned:
+		// WAVE stores 8-bit samples unsigned, and larger samples signed little-endian.
+		if format.Bits <= 8 {
+			format.Encoding = audio.Unsigned
+		} else {
+			format.Encoding = audio.Signed
+		}
+		format.Bits = format.BitsPerSample()
+		format.Container = 0
+	case audio.Float, audio.ALaw, audio.MuLaw:
+	default:
+		return nil, fmt.Errorf("wav: can't write %s samples", format.Encoding)
+	}
+	if format.Bits > 8 {
+		format.ByteOrder = binary.LittleEndian
+	}
+
+	start, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	wr := &Writer[T]{
+		w:      w,
+		format: format,
+		layout: layout,
+		start:  start,
+	}
+	if wr.writer, err = pcm.NewWriter[T](w, format); err != nil {
+		return nil, err
+	}
+	if err = wr.writeHeader(); err != nil {
+		return nil, err
+	}
+	return wr, nil
+}
+
+// Format of the samples in the file.
+func (wr *Writer[T]) Format() audio.Format {
+	return wr.format
+}
+
+func (wr *Writer[T]) String() string {
+	return fmt.Sprintf("wav %s", wr.format)
+}
+
+func (wr *Writer[T]) tag() FormatTag {
+	switch wr.format.Encoding {
+	case audio.Float:
+		return FormatIEEEFloat
+	case audio.ALaw:
+		return FormatALaw
+	case audio.MuLaw:
+		return FormatMuLaw
+	default:
+		return FormatPCM
+	}
+}
+
+// extensible checks if the format needs an extensible format chunk.
+func (wr *Writer[T]) extensible() bool {
+	if wr.format.Encoding == audio.ALaw || wr.format.Encoding == audio.MuLaw {
+		return false
+	}
+	return wr.format.Channels > 2 || wr.format.Bits > 16 ||
+		(len(wr.layout) > 0 && !wr.layout.Equal(audio.DefaultLayout(wr.format.Channels)))
+}
+
+// fmtChunk returns the body of the fmt chunk.
+func (wr *Writer[T]) fmtChunk() []byte {
+	var (
+		f          = wr.format
+		blockAlign = f.BytesPerFrame()
+		tag        = wr.tag()
+		b          []byte
+	)
+	if wr.extensible() {
+		b = binary.LittleEndian.AppendUint16(b, uint16(FormatExtensible))
+	} else {
+		b = binary.LittleEndian.AppendUint16(b, uint16(tag))
+	}
+	b = binary.LittleEndian.AppendUint16(b, uint16(f.Channels))
+	b = binary.LittleEndian.AppendUint32(b, uint32(f.SampleRate))
+	b = binary.LittleEndian.AppendUint32(b, uint32(f.SampleRate*blockAlign))
+	b = binary.LittleEndian.AppendUint16(b, uint16(blockAlign))
+	b = binary.LittleEndian.AppendUint16(b, uint16(f.BitsPerSample()))
+	switch {
+	case wr.extensible():
+		b = binary.LittleEndian.AppendUint16(b, 22)
+		b = binary.LittleEndian.AppendUint16(b, uint16(f.Bits))
+		b = binary.LittleEndian.AppendUint32(b, wr.layout.Mask())
+		b = binary.LittleEndian.AppendUint16(b, uint16(tag))
+		b = append(b, subFormatSuffix[:]...)
+	case tag != FormatPCM:
+		// Formats other than PCM have an extension, which is empty.
+		b = binary.LittleEndian.AppendUint16(b, 0)
+	}
+	return b
+}
+
+// writeHeader writes the RIFF header, a JUNK chunk reserved for the ds64 chunk, and the format
+// and data chunk headers.
+func (wr *Writer[T]) writeHeader() error {
+	b := append([]byte("RIFF\x00\x00\x00\x00WAVE"), "JUNK"...)
+	b = binary.LittleEndian.AppendUint32(b, ds64Size)
+	b = append(b, make([]byte, ds64Size)...)
+	b = append(b, "fmt "...)
+	body := wr.fmtChunk()
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(body)))
+	b = append(b, body...)
+	if wr.format.Encoding != audio.Signed && wr.format.Encoding != audio.Unsigned {
+		// Non-PCM formats have a fact chunk with the number of frames.
+		b = append(b, "fact\x04\x00\x00\x00\x00\x00\x00\x00"...)
+	}
+	b = append(b, "data\x00\x00\x00\x00"...)
+	if _, err := wr.w.Write(b); err != nil {
+		return err
+	}
+	wr.dataOffset = wr.start + int64(len(b))
+	return nil
+}
+
+// WriteSamples writes samples converted to the format of the file.
+func (wr *Writer[T]) WriteSamples(samples audio.Samples[T]) (int, error) {
+	if wr.closed {
+		return 0, ErrClosed
+	}
+	n, err := wr.writer.WriteSamples(samples)
+	wr.size += int64(n * wr.format.BytesPerSample())
+	return n, err
+}
+
+// Frames is the number of frames written.
+func (wr *Writer[T]) Frames() int64 {
+	return wr.size / int64(wr.format.BytesPerFrame())
+}
+
+// Close writes the metadata and patches the sizes in the header. It doesn't close the
+// io.WriteSeeker.
+func (wr *Writer[T]) Close() error {
+	if wr.closed {
+		return nil
+	}
+	wr.closed = true
+
+	end := wr.dataOffset + wr.size
+	if _, err := wr.w.Seek(end, io.SeekStart); err != nil {
+		return err
+	}
+	if wr.size&1 == 1 {
+		if _, err := wr.w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+	if err := wr.Metadata.encode(wr.w); err != nil {
+		return err
+	}
+	end, err := wr.w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	sizes := &ds64{
+		riff:   end - wr.start - 8,
+		data:   wr.size,
+		frames: wr.Frames(),
+	}
+	if sizes.riff > maxRIFFSize {
+		if err = wr.upgrade(sizes); err != nil {
+			return err
+		}
+	} else {
+		if err = wr.patch(wr.start+4, sizes.riff); err != nil {
+			return err
+		}
+		if wr.format.Encoding != audio.Signed && wr.format.Encoding != audio.Unsigned {
+			if err = wr.patch(wr.dataOffset-12, sizes.frames); err != nil {
+				return err
+			}
+		}
+		if err = wr.patch(wr.dataOffset-4, sizes.data); err != nil {
+			return err
+		}
+	}
+	_, err = wr.w.Seek(end, io.SeekStart)
+	return err
+}
+
+// upgrade converts the header to RF64, with the sizes in a ds64 chunk that replaces the JUNK chunk.
+func (wr *Writer[T]) upgrade(sizes *ds64) error {
+	magic := "RF64"
+	if wr.BW64 {
+		magic = "BW64"
+	}
+	header := binary.LittleEndian.AppendUint32([]byte(magic), sizeRF64)
+	if err := wr.writeAt(wr.start, header); err != nil {
+		return err
+	}
+	chunk := binary.LittleEndian.AppendUint32([]byte("ds64"), ds64Size)
+	if err := wr.writeAt(wr.start+12, append(chunk, sizes.encode()...)); err != nil {
+		return err
+	}
+	if wr.format.Encoding != audio.Signed && wr.format.Encoding != audio.Unsigned {
+		if err := wr.patch(wr.dataOffset-12, min(sizes.frames, sizeRF64)); err != nil {
+			return err
+		}
+	}
+	return wr.patch(wr.dataOffset-4, sizeRF64)
+}
+
+// patch writes a size at an offset.
+func (wr *Writer[T]) patch(offset, size int64) error {
+	return wr.writeAt(offset, binary.LittleEndian.AppendUint32(nil, uint32(size)))
+}
+
+// writeAt writes b at an offset.
+func (wr *Writer[T]) writeAt(offset int64, b []byte) error {
+	if _, err := wr.w.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := wr.w.Write(b)
+	return err
+}